@@ -0,0 +1,240 @@
+package keystone
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// CacheConfig configures the entity cache
+type CacheConfig struct {
+	TTL         time.Duration // How long a retrieved entity is cached, defaults to 1 minute
+	NegativeTTL time.Duration // How long a not found entity is cached, defaults to 10 seconds, negative to disable
+	MaxEntries  int           // Entries kept before the least recently used are evicted, defaults to 1000
+}
+
+// EntityCache caches entities retrieved by ID, keyed by workspace, schema, entity ID and view
+type EntityCache struct {
+	mu       sync.Mutex
+	config   CacheConfig
+	entries  map[string]*list.Element
+	lru      *list.List
+	byEntity map[string]map[string]struct{}
+	// generation is incremented by every invalidation, so a retrieve that overlaps one is not cached
+	generation uint64
+}
+
+type cacheEntry struct {
+	key       string
+	entityKey string
+	resp      *proto.EntityResponse
+	err       error
+	expires   time.Time
+}
+
+// EnableCache caches entities retrieved by ID with Get, mutations sent through the connection invalidate the entity
+func (c *Connection) EnableCache(config CacheConfig) *EntityCache {
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = 10 * time.Second
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+
+	c.cache = &EntityCache{
+		config:   config,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		byEntity: make(map[string]map[string]struct{}),
+	}
+	return c.cache
+}
+
+// Cache returns the entity cache, or nil when it has not been enabled
+func (c *Connection) Cache() *EntityCache { return c.cache }
+
+type skipCache struct{}
+
+func (l skipCache) Apply(*proto.EntityView) {}
+
+// SkipCache is a retrieve option that always reads the entity from keystone, refreshing the cache
+func SkipCache() RetrieveOption {
+	return skipCache{}
+}
+
+// Len returns the number of cached entries
+func (e *EntityCache) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lru.Len()
+}
+
+// Invalidate removes every cached view of the entities
+func (e *EntityCache) Invalidate(workspaceID string, entityIDs ...string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.generation++
+	for _, entityID := range entityIDs {
+		if entityID != "" {
+			e.invalidate(cacheEntityKey(workspaceID, entityID))
+		}
+	}
+}
+
+// Purge removes all cached entries
+func (e *EntityCache) Purge() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.generation++
+	e.entries = make(map[string]*list.Element)
+	e.byEntity = make(map[string]map[string]struct{})
+	e.lru.Init()
+}
+
+func (e *EntityCache) invalidate(entityKey string) {
+	for key := range e.byEntity[entityKey] {
+		if el, ok := e.entries[key]; ok {
+			e.remove(el)
+		}
+	}
+}
+
+func (e *EntityCache) remove(el *list.Element) {
+	entry := e.lru.Remove(el).(*cacheEntry)
+	delete(e.entries, entry.key)
+	if keys, ok := e.byEntity[entry.entityKey]; ok {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(e.byEntity, entry.entityKey)
+		}
+	}
+}
+
+func (e *EntityCache) get(key string) (*proto.EntityResponse, error, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	el, ok := e.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		e.remove(el)
+		return nil, nil, false
+	}
+	e.lru.MoveToFront(el)
+	if entry.err != nil {
+		return nil, entry.err, true
+	}
+	return protobuf.Clone(entry.resp).(*proto.EntityResponse), nil, true
+}
+
+// currentGeneration returns the invalidation generation to pass to set for a retrieve about to be sent
+func (e *EntityCache) currentGeneration() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.generation
+}
+
+// set caches the response, unless an invalidation has happened since generation was read
+func (e *EntityCache) set(key, entityKey string, generation uint64, resp *proto.EntityResponse, err error) {
+	ttl := e.config.TTL
+	if err != nil {
+		ttl = e.config.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	if resp != nil {
+		resp = protobuf.Clone(resp).(*proto.EntityResponse)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if generation != e.generation {
+		return
+	}
+	if el, ok := e.entries[key]; ok {
+		e.remove(el)
+	}
+	entry := &cacheEntry{key: key, entityKey: entityKey, resp: resp, err: err, expires: time.Now().Add(ttl)}
+	e.entries[key] = e.lru.PushFront(entry)
+	if e.byEntity[entityKey] == nil {
+		e.byEntity[entityKey] = make(map[string]struct{})
+	}
+	e.byEntity[entityKey][key] = struct{}{}
+
+	for e.lru.Len() > e.config.MaxEntries {
+		e.remove(e.lru.Back())
+	}
+}
+
+func cacheEntityKey(workspaceID, entityID string) string {
+	return workspaceID + "\x00" + entityID
+}
+
+// retrieveCached retrieves the entity through the cache
+// Requests for locks, by unique ID, or for decrypted properties are never cached
+func (c *Connection) retrieveCached(ctx context.Context, in *proto.EntityRequest, useCache bool) (*proto.EntityResponse, error) {
+	key, entityKey, ok := cacheKeys(in)
+	if c.cache == nil || !ok {
+		return c.Retrieve(ctx, in)
+	}
+
+	if useCache {
+		if resp, err, ok := c.cache.get(key); ok {
+			return resp, err
+		}
+	}
+
+	generation := c.cache.currentGeneration()
+	resp, err := c.Retrieve(ctx, in)
+	if err == nil {
+		c.cache.set(key, entityKey, generation, resp, nil)
+	} else if status.Code(err) == codes.NotFound {
+		c.cache.set(key, entityKey, generation, nil, err)
+	}
+	return resp, err
+}
+
+// cacheKeys returns the key the entity request is cached under and the key of its entity, ok is false when the request cannot be cached
+func cacheKeys(in *proto.EntityRequest) (string, string, bool) {
+	if in.GetEntityId() == "" || in.GetRequestLock() || decryptsProperties(in.GetView()) {
+		return "", "", false
+	}
+	view, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(in.GetView())
+	if err != nil {
+		return "", "", false
+	}
+	entityKey := cacheEntityKey(in.GetAuthorization().GetWorkspaceId(), in.GetEntityId())
+	return entityKey + "\x00" + in.GetSchema().GetKey() + "\x00" + string(view), entityKey, true
+}
+
+// decryptsProperties returns true when the view requests decrypted properties, which are readable only by the requesting user
+func decryptsProperties(view *proto.EntityView) bool {
+	for _, p := range view.GetProperties() {
+		if p.GetDecrypt() {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidateParent removes the cached views of the parent when src is a child entity
+func (a *Actor) invalidateParent(src interface{}) {
+	if child, ok := src.(ChildEntity); ok {
+		a.connection.cache.Invalidate(a.workspaceID, child.GetKeystoneParentID())
+	}
+}
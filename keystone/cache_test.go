@@ -0,0 +1,145 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEntityCache(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	retrieves := 0
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		retrieves++
+		if req.GetEntityId() == "missing" {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return &proto.EntityResponse{
+			Entity:     &proto.Entity{EntityId: req.GetEntityId()},
+			Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ant"}}},
+		}, nil
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	cache := conn.EnableCache(CacheConfig{TTL: time.Minute, MaxEntries: 2})
+	actor := conn.Actor("workspace", "", "", "")
+	ctx := context.Background()
+
+	get := func(id string, opts ...RetrieveOption) error {
+		return actor.GetByID(ctx, id, &testTypedEntity{}, append([]RetrieveOption{WithProperties("name")}, opts...)...)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get("a"); err != nil {
+			t.Fatal(err)
+		}
+		if err := get("missing"); status.Code(err) != codes.NotFound {
+			t.Fatal("Expected not found, got", err)
+		}
+	}
+	if retrieves != 2 {
+		t.Error("Expected cached and not found entities to be retrieved once, got", retrieves)
+	}
+
+	if err := get("a", SkipCache()); err != nil {
+		t.Fatal(err)
+	}
+	if retrieves != 3 {
+		t.Error("Expected SkipCache to retrieve from keystone, got", retrieves)
+	}
+
+	if err := actor.SetDynamicProperties(ctx, "a", nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if retrieves != 4 {
+		t.Error("Expected mutation to invalidate the cached entity, got", retrieves)
+	}
+
+	_ = get("b")
+	_ = get("c")
+	if cache.Len() != 2 {
+		t.Error("Expected the cache to be limited to 2 entries, got", cache.Len())
+	}
+}
+
+type testCacheChild struct {
+	BaseChildEntity
+	Name string
+}
+
+func TestEntityCache_Invalidate(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+	retrieves := 0
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		retrieves++
+		return &proto.EntityResponse{Entity: &proto.Entity{EntityId: req.GetEntityId()}}, nil
+	}
+
+	cache := conn.EnableCache(CacheConfig{})
+	actor := conn.Actor("workspace", "", "", "")
+	ctx := context.Background()
+
+	// a retrieve that overlaps an invalidation must not cache the stale response
+	generation := cache.currentGeneration()
+	cache.Invalidate("workspace", "a")
+	cache.set("a-view", cacheEntityKey("workspace", "a"), generation, &proto.EntityResponse{}, nil)
+	if cache.Len() != 0 {
+		t.Error("Expected a response retrieved before an invalidation not to be cached")
+	}
+
+	// UUID like IDs are not split into a parent
+	uuid := "2c1b2bde-7d10-4a4b-9f3a-5d1d0c3f4e2a"
+	cache.set("parent-view", cacheEntityKey("workspace", "2c1b2bde"), cache.currentGeneration(), &proto.EntityResponse{}, nil)
+	if err := actor.SetDynamicProperties(ctx, uuid, nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 {
+		t.Error("Expected mutating a UUID entity to leave other entities cached")
+	}
+
+	// mutating a child entity invalidates its parent
+	cache.set("parent-view", cacheEntityKey("workspace", "parent"), cache.currentGeneration(), &proto.EntityResponse{}, nil)
+	child := &testCacheChild{Name: "child"}
+	child.SetKeystoneParentID("parent")
+	child.SetKeystoneChildID("child")
+	if err := actor.Mutate(ctx, child, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.get("parent-view"); ok {
+		t.Error("Expected mutating a child to invalidate its parent")
+	}
+
+	// decrypted properties are never cached
+	for i := 0; i < 2; i++ {
+		if err := actor.GetByID(ctx, "a", &testTypedEntity{}, WithDecryptedProperties("name")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if retrieves != 2 {
+		t.Error("Expected decrypted views to be retrieved every time, got", retrieves)
+	}
+}
@@ -0,0 +1,122 @@
+package keystone
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// ChildSummary receives the aggregates of an entity's children, requested with WithChildSummary
+// Tag the field with keystone:"_child_summary:child-type" to select the child type, or keystone:"_child_summary" for all children
+type ChildSummary struct {
+	Count int64
+	Sum   int64
+	Min   int64
+	Max   int64
+	Avg   int64
+}
+
+var typeOfChildSummary = reflect.TypeOf(ChildSummary{})
+
+// isChildSummaryType returns true for ChildSummary and *ChildSummary
+func isChildSummaryType(t reflect.Type) bool {
+	return t == typeOfChildSummary || (t.Kind() == reflect.Pointer && t.Elem() == typeOfChildSummary)
+}
+
+// childSummaryKey returns the child type a summary field is tagged with, empty for all children
+func childSummaryKey(name string) string {
+	key, _ := strings.CutPrefix(name, "_child_summary")
+	return strings.TrimPrefix(key, ":")
+}
+
+// hasChildSummaryField returns true if the type declares a child summary field
+func hasChildSummaryField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if hasChildSummaryField(field.Type) {
+				return true
+			}
+			continue
+		}
+		if field.IsExported() && getFieldOptions(field, "").childSummary {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalChildSummaries sets the child summary fields of dst from the summaries matching their child type
+func unmarshalChildSummaries(summaries []*proto.ChildSummary, dst interface{}) {
+	if len(summaries) == 0 {
+		return
+	}
+
+	byKey := make(map[string]*proto.ChildSummary)
+	for _, summary := range summaries {
+		t := summary.GetType()
+		if t.GetKey() == "" {
+			byKey[""] = summary
+			continue
+		}
+		byKey[fmt.Sprintf("%s:%s:%s", t.GetSource().GetVendorId(), t.GetSource().GetAppId(), t.GetKey())] = summary
+		byKey[fmt.Sprintf("%s:%s", t.GetSource().GetAppId(), t.GetKey())] = summary
+		byKey[t.GetKey()] = summary
+	}
+	setChildSummaries(byKey, reflect.ValueOf(dst))
+}
+
+func setChildSummaries(byKey map[string]*proto.ChildSummary, value reflect.Value) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			setChildSummaries(byKey, value.Field(i))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, "")
+		if !fOpt.childSummary || fOpt.name == "" || !value.Field(i).CanSet() {
+			continue
+		}
+		summary, ok := byKey[childSummaryKey(fOpt.name)]
+		if !ok {
+			continue
+		}
+
+		result := ChildSummary{
+			Count: summary.GetCount(),
+			Sum:   summary.GetSum(),
+			Min:   summary.GetMin(),
+			Max:   summary.GetMax(),
+			Avg:   summary.GetAvg(),
+		}
+		if field.Type.Kind() == reflect.Pointer {
+			value.Field(i).Set(reflect.ValueOf(&result))
+		} else {
+			value.Field(i).Set(reflect.ValueOf(result))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package keystone
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testSummaryEntity struct {
+	BaseEntity
+	Name      string
+	LineItems ChildSummary  `keystone:"_child_summary:line-items"`
+	Payments  *ChildSummary `keystone:"_child_summary:payments"`
+	All       ChildSummary  `keystone:"_child_summary"`
+}
+
+func TestWithChildSummary(t *testing.T) {
+	view := &proto.EntityView{}
+	WithChildSummary().Apply(view)
+	if !view.GetChildSummary() || view.GetRelationshipCount() {
+		t.Error("Expected only the child summary to be requested", view)
+	}
+}
+
+func TestChildSummaryUnmarshal(t *testing.T) {
+	for _, property := range getProperties(reflect.TypeOf(testSummaryEntity{}), "") {
+		if property.GetName() != "name" {
+			t.Error("Expected child summaries to be excluded from the schema, got", property.GetName())
+		}
+	}
+
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		if !req.GetView().GetChildSummary() {
+			t.Error("Expected the child summary to be requested")
+		}
+		return &proto.FindResponse{Entities: []*proto.EntityResponse{{
+			Entity:     &proto.Entity{EntityId: "a"},
+			Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Invoice"}}},
+			ChildSummary: []*proto.ChildSummary{
+				{Type: &proto.Key{Key: "line-items", Source: &proto.VendorApp{VendorId: "vendor", AppId: "app"}}, Count: 3, Sum: 600, Min: 100, Max: 300, Avg: 200},
+				{Type: &proto.Key{Key: "payments"}, Count: 1, Sum: 600, Min: 600, Max: 600, Avg: 600},
+				{Count: 4, Sum: 1200, Min: 100, Max: 600, Avg: 300},
+			},
+		}}}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	result, err := FindAll[testSummaryEntity](context.Background(), &actor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 {
+		t.Fatal("Expected 1 entity, got", len(result))
+	}
+
+	entity := result[0]
+	if entity.Name != "Invoice" {
+		t.Error("Expected name Invoice, got", entity.Name)
+	}
+	if entity.LineItems != (ChildSummary{Count: 3, Sum: 600, Min: 100, Max: 300, Avg: 200}) {
+		t.Error("Unexpected line item summary", entity.LineItems)
+	}
+	if entity.Payments == nil || entity.Payments.Count != 1 || entity.Payments.Sum != 600 {
+		t.Error("Unexpected payment summary", entity.Payments)
+	}
+	if entity.All.Count != 4 || entity.All.Avg != 300 {
+		t.Error("Unexpected summary of all children", entity.All)
+	}
+}
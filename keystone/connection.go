@@ -7,21 +7,31 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	protobuf "google.golang.org/protobuf/proto"
 	"log"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Connection is a connection to a keystone server
 type Connection struct {
-	client        proto.KeystoneClient
-	logger        *logger.Logger
-	timeLogConfig *logger.TimedLogConfig
-	appID         proto.VendorApp
-	token         string
-	typeRegister  map[reflect.Type]schemaDef
-	registerQueue map[reflect.Type]bool // true if the type is processing registration
+	client             proto.KeystoneClient
+	logger             *logger.Logger
+	timeLogConfig      *logger.TimedLogConfig
+	appID              proto.VendorApp
+	token              string
+	registerLock       sync.RWMutex // guards typeRegister, registerQueue and projections
+	typeRegister       map[reflect.Type]schemaDef
+	registerQueue      map[reflect.Type]bool // true if the type is processing registration
+	idempotency        *idempotencyCache
+	maxFutureTimestamp atomic.Int64
+	findBatchSize      atomic.Int64
+	maxRelationDepth   atomic.Int64
+	outbox             *Outbox
+	projections        map[reflect.Type]projection
+	cache              *EntityCache
 }
 
 func DefaultConnection(host, port, vendorID, appID, accessToken string) *Connection {
@@ -49,6 +59,8 @@ func NewConnection(client proto.KeystoneClient, vendorID, appID, accessToken str
 		token:         accessToken,
 		typeRegister:  make(map[reflect.Type]schemaDef),
 		registerQueue: make(map[reflect.Type]bool),
+		idempotency:   newIdempotencyCache(DefaultIdempotencyWindow, DefaultIdempotencyLimit),
+		projections:   make(map[reflect.Type]projection),
 	}
 }
 
@@ -63,19 +75,61 @@ func (c *Connection) Define(ctx context.Context, in *proto.SchemaRequest, opts .
 }
 
 func (c *Connection) Mutate(ctx context.Context, in *proto.MutateRequest, opts ...grpc.CallOption) (*proto.MutateResponse, error) {
+	if c.outbox == nil {
+		return c.mutate(ctx, in, opts...)
+	}
+
+	ctx, key := outboxIdempotencyKey(ctx)
+	if c.outbox.queueing() {
+		return nil, c.outbox.queue(outboxMutate, key, in)
+	}
+	resp, err := c.mutate(ctx, in, opts...)
+	if isUnavailable(err) {
+		return nil, c.outbox.queue(outboxMutate, key, in)
+	}
+	return resp, err
+}
+
+func (c *Connection) mutate(ctx context.Context, in *proto.MutateRequest, opts ...grpc.CallOption) (*proto.MutateResponse, error) {
 	tl := c.timeLogConfig.NewLog("Mutate", zap.String("EntityId", in.GetEntityId()))
 	resp, err := c.client.Mutate(ctx, in, opts...)
 	c.logger.TimedLog(tl)
+	c.cache.Invalidate(in.GetAuthorization().GetWorkspaceId(), mutatedEntityIDs(in.GetEntityId(), resp)...)
 	return resp, err
 }
 
 func (c *Connection) ReportTimeSeries(ctx context.Context, in *proto.ReportTimeSeriesRequest, opts ...grpc.CallOption) (*proto.MutateResponse, error) {
+	if c.outbox == nil {
+		return c.reportTimeSeries(ctx, in, opts...)
+	}
+
+	ctx, key := outboxIdempotencyKey(ctx)
+	if c.outbox.queueing() {
+		return nil, c.outbox.queue(outboxTimeSeries, key, in)
+	}
+	resp, err := c.reportTimeSeries(ctx, in, opts...)
+	if isUnavailable(err) {
+		return nil, c.outbox.queue(outboxTimeSeries, key, in)
+	}
+	return resp, err
+}
+
+func (c *Connection) reportTimeSeries(ctx context.Context, in *proto.ReportTimeSeriesRequest, opts ...grpc.CallOption) (*proto.MutateResponse, error) {
 	tl := c.timeLogConfig.NewLog("ReportTimeSeries", zap.String("EntityId", in.GetEntityId()))
 	resp, err := c.client.ReportTimeSeries(ctx, in, opts...)
 	c.logger.TimedLog(tl)
+	c.cache.Invalidate(in.GetAuthorization().GetWorkspaceId(), mutatedEntityIDs(in.GetEntityId(), resp)...)
 	return resp, err
 }
 
+// mutatedEntityIDs returns the requested entity ID, and the ID keystone responded with when it differs
+func mutatedEntityIDs(entityID string, resp *proto.MutateResponse) []string {
+	if resp.GetEntityId() != "" && resp.GetEntityId() != entityID {
+		return []string{entityID, resp.GetEntityId()}
+	}
+	return []string{entityID}
+}
+
 func (c *Connection) Retrieve(ctx context.Context, in *proto.EntityRequest, opts ...grpc.CallOption) (*proto.EntityResponse, error) {
 	tl := c.timeLogConfig.NewLog("Retrieve", zap.String("EntityId", in.GetEntityId()))
 	resp, err := c.client.Retrieve(ctx, in, opts...)
@@ -84,6 +138,22 @@ func (c *Connection) Retrieve(ctx context.Context, in *proto.EntityRequest, opts
 }
 
 func (c *Connection) Log(ctx context.Context, in *proto.LogRequest, opts ...grpc.CallOption) (*proto.LogResponse, error) {
+	if c.outbox == nil {
+		return c.log(ctx, in, opts...)
+	}
+
+	ctx, key := outboxIdempotencyKey(ctx)
+	if c.outbox.queueing() {
+		return nil, c.outbox.queue(outboxLog, key, in)
+	}
+	resp, err := c.log(ctx, in, opts...)
+	if isUnavailable(err) {
+		return nil, c.outbox.queue(outboxLog, key, in)
+	}
+	return resp, err
+}
+
+func (c *Connection) log(ctx context.Context, in *proto.LogRequest, opts ...grpc.CallOption) (*proto.LogResponse, error) {
 	tl := c.timeLogConfig.NewLog("Logs", zap.String("EntityId", in.GetEntityId()))
 	resp, err := c.client.Log(ctx, in, opts...)
 	c.logger.TimedLog(tl)
@@ -174,45 +244,110 @@ func (c *Connection) registerType(t interface{}) (*proto.Schema, bool) {
 		typ = typ.Elem()
 	}
 
+	c.registerLock.Lock()
+	defer c.registerLock.Unlock()
 	sDef, ok := c.typeRegister[typ]
 	if !ok {
 		newDef := typeToSchema(t)
 		c.typeRegister[typ] = newDef
+		c.registerProjections(typ, newDef.definition)
 		c.registerQueue[typ] = false
 		return newDef.schema, false
 	}
 	return sDef.schema, true
 }
 
+// definedSchema registers the type of dst, waiting for a newly registered type to be defined with the keystone server
+func (c *Connection) definedSchema(dst interface{}) *proto.Schema {
+	if _, registered := c.registerType(dst); !registered {
+		c.SyncSchema().Wait()
+	}
+	return c.plannedSchema(dst)
+}
+
+// plannedSchema returns the schema registered for src, or the schema it would register, without registering it
+func (c *Connection) plannedSchema(src interface{}) *proto.Schema {
+	typ := reflect.TypeOf(src)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	c.registerLock.RLock()
+	sDef, ok := c.typeRegister[typ]
+	c.registerLock.RUnlock()
+	if ok {
+		return sDef.schema
+	}
+	return typeToSchema(src).schema
+}
+
+// registeredSchema returns the registered schema for the entity type, or nil when the type is not registered
+func (c *Connection) registeredSchema(entityType string) *proto.Schema {
+	c.registerLock.RLock()
+	defer c.registerLock.RUnlock()
+	for _, sDef := range c.typeRegister {
+		if sDef.schema.GetType() == entityType {
+			return sDef.schema
+		}
+	}
+	return nil
+}
+
 // SyncSchema syncs the schema with the server
 func (c *Connection) SyncSchema() *sync.WaitGroup {
+	c.registerLock.Lock()
+	var pending []reflect.Type
+	for typ, processing := range c.registerQueue {
+		if !processing {
+			c.registerQueue[typ] = true
+			pending = append(pending, typ)
+		}
+	}
+	c.registerLock.Unlock()
+
 	wg := &sync.WaitGroup{}
-	wg.Add(len(c.registerQueue))
+	wg.Add(len(pending))
 	go func() {
-		for typ, processing := range c.registerQueue {
-			if !processing {
-				if toRegister, ok := c.typeRegister[typ]; ok {
-					resp, err := c.Define(context.Background(), &proto.SchemaRequest{
-						Authorization: c.authorization(),
-						Schema:        toRegister.schema,
-						Views:         toRegister.definition.Views,
-					})
-
-					if err == nil {
-						c.typeRegister[typ].schema.Id = resp.GetId()
-						c.typeRegister[typ].schema.Name = resp.GetName()
-						c.typeRegister[typ].schema.Source = resp.GetSource()
-						c.typeRegister[typ].schema.Type = resp.GetType()
-						c.typeRegister[typ].schema.Properties = resp.GetProperties()
-						c.typeRegister[typ].schema.Options = resp.GetOptions()
-						c.typeRegister[typ].schema.Singular = resp.GetSingular()
-						c.typeRegister[typ].schema.Plural = resp.GetPlural()
-					}
-
-				}
-				wg.Done()
-			}
+		for _, typ := range pending {
+			c.defineType(typ)
+			wg.Done()
 		}
 	}()
 	return wg
 }
+
+// defineType defines the registered type with the server, replacing its schema with the defined schema
+// Schemas handed out before the type was defined are never modified, a failed definition is retried by the next sync
+func (c *Connection) defineType(typ reflect.Type) {
+	c.registerLock.RLock()
+	toRegister, ok := c.typeRegister[typ]
+	c.registerLock.RUnlock()
+	if !ok {
+		return
+	}
+
+	resp, err := c.Define(context.Background(), &proto.SchemaRequest{
+		Authorization: c.authorization(),
+		Schema:        toRegister.schema,
+		Views:         toRegister.definition.Views,
+	})
+
+	c.registerLock.Lock()
+	defer c.registerLock.Unlock()
+	if err != nil {
+		c.registerQueue[typ] = false
+		return
+	}
+
+	defined := protobuf.Clone(toRegister.schema).(*proto.Schema)
+	defined.Id = resp.GetId()
+	defined.Name = resp.GetName()
+	defined.Source = resp.GetSource()
+	defined.Type = resp.GetType()
+	defined.Properties = resp.GetProperties()
+	defined.Options = resp.GetOptions()
+	defined.Singular = resp.GetSingular()
+	defined.Plural = resp.GetPlural()
+	toRegister.schema = defined
+	c.typeRegister[typ] = toRegister
+	delete(c.registerQueue, typ)
+}
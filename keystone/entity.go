@@ -51,6 +51,15 @@ func (e *BaseEntity) SetKeystoneID(id string) {
 	e._entityID = id
 }
 
+func (e *BaseEntity) keystoneLastLoad() *proto.EntityResponse        { return e._lastLoad }
+func (e *BaseEntity) setKeystoneLastLoad(resp *proto.EntityResponse) { e._lastLoad = resp }
+
+// loadTracker is implemented by entities that keep the last response they were loaded from
+type loadTracker interface {
+	keystoneLastLoad() *proto.EntityResponse
+	setKeystoneLastLoad(resp *proto.EntityResponse)
+}
+
 type testEntity struct {
 	BaseEntity
 }
@@ -0,0 +1,154 @@
+package keystone
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// MaxDatumSize is the maximum size in bytes of an encoded entity datum
+const MaxDatumSize = 1024 * 1024
+
+// clearedDatum is written to remove the datum of an entity, decoding it leaves the datum field at its zero value
+var clearedDatum = []byte("null")
+
+// datumField returns the field tagged with keystone:",datum", searching embedded structs
+func datumField(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if fv, ok := datumField(value.Field(i)); ok {
+				return fv, true
+			}
+			continue
+		}
+		if field.IsExported() && getFieldOptions(field, "").datum {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// hasDatumField returns true if the type declares a datum field
+func hasDatumField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if hasDatumField(field.Type) {
+				return true
+			}
+			continue
+		}
+		if field.IsExported() && getFieldOptions(field, "").datum {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalDatum JSON encodes the datum field of src, returning nil when there is no datum to write
+// The datum is only written when it differs from the datum src was loaded with, a zero datum on an entity
+// loaded with a datum is written as clearedDatum
+func marshalDatum(src interface{}) ([]byte, error) {
+	field, ok := datumField(reflect.ValueOf(src))
+	if !ok {
+		return nil, nil
+	}
+
+	previous := loadedDatum(src)
+	if field.IsZero() {
+		if previous == nil {
+			return nil, nil
+		}
+		return clearedDatum, nil
+	}
+
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxDatumSize {
+		return nil, fmt.Errorf("datum is %d bytes, exceeding the maximum of %d bytes", len(data), MaxDatumSize)
+	}
+	if previous != nil && datumEqual(previous, data) {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// datumEqual returns true when both encoded datums hold the same JSON value
+func datumEqual(a, b []byte) bool {
+	var aValue, bValue any
+	if json.Unmarshal(a, &aValue) != nil || json.Unmarshal(b, &bValue) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// isClearedDatum returns true when there is no datum, or it has been cleared
+func isClearedDatum(data []byte) bool {
+	return len(data) == 0 || bytes.Equal(data, clearedDatum)
+}
+
+// unmarshalDatum JSON decodes the datum onto the datum field of dst
+func unmarshalDatum(datum *proto.EntityDatum, dst interface{}) error {
+	if len(datum.GetData()) == 0 {
+		return nil
+	}
+
+	field, ok := datumField(reflect.ValueOf(dst))
+	if !ok || !field.CanAddr() {
+		return nil
+	}
+	if isClearedDatum(datum.GetData()) {
+		field.SetZero()
+		return nil
+	}
+	return json.Unmarshal(datum.GetData(), field.Addr().Interface())
+}
+
+// loadedDatum returns the datum src was last loaded with, or nil when it was loaded without one
+func loadedDatum(src interface{}) []byte {
+	tracker, ok := src.(loadTracker)
+	if !ok || isClearedDatum(tracker.keystoneLastLoad().GetDatum().GetData()) {
+		return nil
+	}
+	return tracker.keystoneLastLoad().GetDatum().GetData()
+}
+
+// datumLoaderFor requests the datum when t declares a datum field, or returns nil when it does not
+func datumLoaderFor(t reflect.Type) RetrieveOption {
+	if !hasDatumField(t) {
+		return nil
+	}
+	return WithDatum()
+}
+
+// datumFromSource returns true if the datum was written by the given source, or has no source
+func datumFromSource(datum *proto.EntityDatum, source *proto.VendorApp) bool {
+	if datum.GetSource() == nil {
+		return true
+	}
+	return datum.GetSource().GetVendorId() == source.GetVendorId() && datum.GetSource().GetAppId() == source.GetAppId()
+}
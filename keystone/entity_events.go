@@ -30,9 +30,14 @@ func (e *EntityEvents) GetKeystoneEvents() []*proto.EntityEvent {
 
 // AddKeystoneEvent adds an event
 func (e *EntityEvents) AddKeystoneEvent(eventType string, properties map[string]string) {
+	e.AddKeystoneEventAt(eventType, properties, time.Now())
+}
+
+// AddKeystoneEventAt adds an event that occurred at the given time
+func (e *EntityEvents) AddKeystoneEventAt(eventType string, properties map[string]string, at time.Time) {
 	e.ksEntityEvents = append(e.ksEntityEvents, &proto.EntityEvent{
 		Type: &proto.Key{Key: eventType},
-		Time: timestamppb.New(time.Now()),
+		Time: timestamppb.New(at),
 		Data: properties,
 	})
 }
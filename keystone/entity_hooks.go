@@ -0,0 +1,53 @@
+package keystone
+
+import "context"
+
+// HookError is returned when an entity hook fails, Stage names the hook
+// An afterMutate HookError means the mutation was committed to keystone, so it must not be retried
+type HookError struct {
+	Stage string
+	Err   error
+}
+
+func (e *HookError) Error() string { return e.Stage + " hook failed: " + e.Err.Error() }
+
+func (e *HookError) Unwrap() error { return e.Err }
+
+// BeforeMutateHook is an interface for entities that need to run logic before being mutated, returning an error aborts the mutation
+type BeforeMutateHook interface {
+	BeforeKeystoneMutate(ctx context.Context, actor *Actor) error
+}
+
+// AfterMutateHook is an interface for entities that need to run logic after a successful mutation
+// An error is returned from the mutation as a HookError, the mutation has still been committed
+type AfterMutateHook interface {
+	AfterKeystoneMutate(ctx context.Context, actor *Actor) error
+}
+
+// AfterLoadHook is an interface for entities that need to run logic after being loaded, actor is nil when unmarshalled directly
+type AfterLoadHook interface {
+	AfterKeystoneLoad(ctx context.Context, actor *Actor) error
+}
+
+func beforeMutate(ctx context.Context, actor *Actor, src interface{}) error {
+	if hook, ok := src.(BeforeMutateHook); ok {
+		return hook.BeforeKeystoneMutate(ctx, actor)
+	}
+	return nil
+}
+
+func afterMutate(ctx context.Context, actor *Actor, src interface{}) error {
+	if hook, ok := src.(AfterMutateHook); ok {
+		if err := hook.AfterKeystoneMutate(ctx, actor); err != nil {
+			return &HookError{Stage: "afterMutate", Err: err}
+		}
+	}
+	return nil
+}
+
+func afterLoad(ctx context.Context, actor *Actor, dst interface{}) error {
+	if hook, ok := dst.(AfterLoadHook); ok {
+		return hook.AfterKeystoneLoad(ctx, actor)
+	}
+	return nil
+}
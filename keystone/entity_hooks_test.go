@@ -0,0 +1,74 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+var errHookAbort = errors.New("abort")
+
+type testHookEntity struct {
+	BaseEntity
+	Name   string
+	loaded bool
+}
+
+func (e *testHookEntity) BeforeKeystoneMutate(context.Context, *Actor) error { return errHookAbort }
+
+func (e *testHookEntity) AfterKeystoneLoad(context.Context, *Actor) error {
+	e.loaded = true
+	return nil
+}
+
+func TestBeforeMutateHookAborts(t *testing.T) {
+	a := &Actor{}
+	if err := a.Mutate(context.Background(), &testHookEntity{}, "test"); !errors.Is(err, errHookAbort) {
+		t.Error("Expected hook error, got", err)
+	}
+}
+
+func TestAfterLoadHook(t *testing.T) {
+	dst := &testHookEntity{}
+	if err := Unmarshal(&proto.EntityResponse{}, dst); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.loaded {
+		t.Error("Expected AfterKeystoneLoad to be called")
+	}
+}
+
+type testAfterMutateEntity struct {
+	BaseEntity
+	Name string
+}
+
+func (e *testAfterMutateEntity) AfterKeystoneMutate(context.Context, *Actor) error {
+	return errHookAbort
+}
+
+func TestAfterMutateHookError(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		return &proto.MutateResponse{Success: true, EntityId: "abc"}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	src := &testAfterMutateEntity{Name: "hooked"}
+	err := actor.Mutate(context.Background(), src, "test")
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Stage != "afterMutate" || !errors.Is(err, errHookAbort) {
+		t.Fatal("Expected an afterMutate HookError, got", err)
+	}
+	if src.GetKeystoneID() != "abc" {
+		t.Error("Expected the committed mutation to set the entity ID, got", src.GetKeystoneID())
+	}
+}
@@ -1,6 +1,8 @@
 package keystone
 
 import (
+	"slices"
+
 	"github.com/kubex/keystone-go/proto"
 )
 
@@ -10,14 +12,30 @@ type EntityLabelProvider interface {
 	GetKeystoneLabels() []*proto.EntityLabel
 }
 
+// EntityLabelSyncer is an interface for entities that can track label changes against the loaded labels
+type EntityLabelSyncer interface {
+	HydrateKeystoneLabels(labels []*proto.EntityLabel)
+	GetKeystoneLabelChanges() (add, remove []*proto.EntityLabel)
+}
+
+// labelCommitter is implemented by entities that track label changes, to mark sent changes as loaded
+type labelCommitter interface {
+	commitKeystoneLabels()
+}
+
 // EntityLabels is a struct that implements EntityLabelProvider
 type EntityLabels struct {
-	ksEntityLabels []*proto.EntityLabel
+	ksEntityLabels       []*proto.EntityLabel
+	ksEntityRemoveLabels []*proto.EntityLabel
+	ksLoadedLabels       []*proto.EntityLabel
+	ksReplaceLabels      bool
 }
 
 // ClearKeystoneLabels clears the labels
 func (e *EntityLabels) ClearKeystoneLabels() error {
 	e.ksEntityLabels = []*proto.EntityLabel{}
+	e.ksEntityRemoveLabels = []*proto.EntityLabel{}
+	e.ksReplaceLabels = false
 	return nil
 }
 
@@ -28,8 +46,96 @@ func (e *EntityLabels) GetKeystoneLabels() []*proto.EntityLabel {
 
 // AddKeystoneLabel adds a label
 func (e *EntityLabels) AddKeystoneLabel(name, value string) {
+	e.ksEntityRemoveLabels = removeLabel(e.ksEntityRemoveLabels, name, value)
 	e.ksEntityLabels = append(e.ksEntityLabels, &proto.EntityLabel{
 		Name:  name,
 		Value: value,
 	})
 }
+
+// RemoveKeystoneLabel removes a label
+func (e *EntityLabels) RemoveKeystoneLabel(name, value string) {
+	e.ksEntityLabels = removeLabel(e.ksEntityLabels, name, value)
+	e.ksEntityRemoveLabels = append(e.ksEntityRemoveLabels, &proto.EntityLabel{
+		Name:  name,
+		Value: value,
+	})
+}
+
+// ReplaceKeystoneLabels replaces all labels, removing any loaded labels that are not provided
+func (e *EntityLabels) ReplaceKeystoneLabels(labels map[string]string) {
+	e.ksEntityLabels = make([]*proto.EntityLabel, 0, len(labels))
+	e.ksEntityRemoveLabels = []*proto.EntityLabel{}
+	for name, value := range labels {
+		e.ksEntityLabels = append(e.ksEntityLabels, &proto.EntityLabel{Name: name, Value: value})
+	}
+	e.ksReplaceLabels = true
+}
+
+// HydrateKeystoneLabels sets the labels loaded from keystone
+func (e *EntityLabels) HydrateKeystoneLabels(labels []*proto.EntityLabel) {
+	e.ksLoadedLabels = append([]*proto.EntityLabel{}, labels...)
+	e.ksEntityLabels = append([]*proto.EntityLabel{}, labels...)
+	e.ksEntityRemoveLabels = []*proto.EntityLabel{}
+	e.ksReplaceLabels = false
+}
+
+// GetKeystoneLabelChanges returns the labels to add and remove, compared to the loaded labels
+func (e *EntityLabels) GetKeystoneLabelChanges() (add, remove []*proto.EntityLabel) {
+	loaded := make(map[string]bool, len(e.ksLoadedLabels))
+	for _, l := range e.ksLoadedLabels {
+		loaded[labelKey(l)] = true
+	}
+
+	current := make(map[string]bool, len(e.ksEntityLabels))
+	for _, l := range e.ksEntityLabels {
+		current[labelKey(l)] = true
+		if !loaded[labelKey(l)] {
+			add = append(add, l)
+		}
+	}
+
+	remove = append(remove, e.ksEntityRemoveLabels...)
+	if e.ksReplaceLabels {
+		for _, l := range e.ksLoadedLabels {
+			if !current[labelKey(l)] {
+				remove = append(remove, l)
+			}
+		}
+	}
+	return add, remove
+}
+
+// commitKeystoneLabels marks the label changes as sent, so they are loaded rather than staged
+func (e *EntityLabels) commitKeystoneLabels() {
+	add, remove := e.GetKeystoneLabelChanges()
+	removed := make(map[string]bool, len(remove))
+	for _, l := range remove {
+		removed[labelKey(l)] = true
+	}
+
+	var loaded []*proto.EntityLabel
+	for _, l := range slices.Concat(e.ksLoadedLabels, add) {
+		if !removed[labelKey(l)] {
+			loaded = append(loaded, l)
+		}
+	}
+	e.ksLoadedLabels = loaded
+	e.ksEntityLabels = append([]*proto.EntityLabel{}, loaded...)
+	e.ksEntityRemoveLabels = []*proto.EntityLabel{}
+	e.ksReplaceLabels = false
+}
+
+func labelKey(l *proto.EntityLabel) string {
+	return l.GetName() + "=" + l.GetValue()
+}
+
+func removeLabel(labels []*proto.EntityLabel, name, value string) []*proto.EntityLabel {
+	var keep []*proto.EntityLabel
+	for _, l := range labels {
+		if l.GetName() != name || l.GetValue() != value {
+			keep = append(keep, l)
+		}
+	}
+	return keep
+}
@@ -0,0 +1,58 @@
+package keystone
+
+import (
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestEntityLabels_ReplaceKeystoneLabels(t *testing.T) {
+	e := &EntityLabels{}
+	e.HydrateKeystoneLabels([]*proto.EntityLabel{
+		{Name: "env", Value: "dev"},
+		{Name: "team", Value: "core"},
+	})
+
+	e.ReplaceKeystoneLabels(map[string]string{"env": "prod", "team": "core"})
+	add, remove := e.GetKeystoneLabelChanges()
+
+	if len(add) != 1 || add[0].GetValue() != "prod" {
+		t.Error("Expected env=prod to be added, got", add)
+	}
+	if len(remove) != 1 || remove[0].GetValue() != "dev" {
+		t.Error("Expected env=dev to be removed, got", remove)
+	}
+}
+
+func TestEntityLabels_RemoveKeystoneLabel(t *testing.T) {
+	e := &EntityLabels{}
+	e.AddKeystoneLabel("env", "dev")
+	e.RemoveKeystoneLabel("env", "dev")
+	add, remove := e.GetKeystoneLabelChanges()
+
+	if len(add) != 0 {
+		t.Error("Expected no labels to be added, got", add)
+	}
+	if len(remove) != 1 {
+		t.Error("Expected 1 label to be removed, got", remove)
+	}
+}
+
+func TestEntityLabels_Commit(t *testing.T) {
+	e := &EntityLabels{}
+	e.HydrateKeystoneLabels([]*proto.EntityLabel{{Name: "env", Value: "dev"}})
+	e.AddKeystoneLabel("team", "core")
+	e.RemoveKeystoneLabel("env", "dev")
+	e.commitKeystoneLabels()
+
+	add, remove := e.GetKeystoneLabelChanges()
+	if len(add) != 0 || len(remove) != 0 {
+		t.Error("Expected no changes after commit, got", add, remove)
+	}
+
+	e.ReplaceKeystoneLabels(map[string]string{})
+	_, remove = e.GetKeystoneLabelChanges()
+	if len(remove) != 1 || remove[0].GetName() != "team" {
+		t.Error("Expected the committed team label to be removed, got", remove)
+	}
+}
@@ -1,6 +1,7 @@
 package keystone
 
 import (
+	"slices"
 	"time"
 
 	"github.com/kubex/keystone-go/proto"
@@ -14,14 +15,30 @@ type EntityRelationshipProvider interface {
 	SetKeystoneRelationships(links []*proto.EntityRelationship)
 }
 
+// EntityRelationshipSyncer is an interface for entities that can track relationship changes against the loaded relationships
+type EntityRelationshipSyncer interface {
+	HydrateKeystoneRelationships(links []*proto.EntityRelationship)
+	GetKeystoneRelationshipChanges() (add, remove []*proto.EntityRelationship)
+}
+
+// relationshipCommitter is implemented by entities that track relationship changes, to mark sent changes as loaded
+type relationshipCommitter interface {
+	commitKeystoneRelationships()
+}
+
 // EntityRelationships is a struct that implements EntityRelationshipProvider
 type EntityRelationships struct {
-	ksEntityRelationships []*proto.EntityRelationship
+	ksEntityRelationships       []*proto.EntityRelationship
+	ksEntityRemoveRelationships []*proto.EntityRelationship
+	ksLoadedRelationships       []*proto.EntityRelationship
+	ksReplaceRelationships      bool
 }
 
 // ClearKeystoneRelationships clears the relationships
 func (e *EntityRelationships) ClearKeystoneRelationships() error {
 	e.ksEntityRelationships = []*proto.EntityRelationship{}
+	e.ksEntityRemoveRelationships = []*proto.EntityRelationship{}
+	e.ksReplaceRelationships = false
 	return nil
 }
 
@@ -32,11 +49,12 @@ func (e *EntityRelationships) GetKeystoneRelationships() []*proto.EntityRelation
 
 // SetKeystoneRelationships sets the relationships
 func (e *EntityRelationships) SetKeystoneRelationships(links []*proto.EntityRelationship) {
-	e.ksEntityRelationships = links
+	e.ksEntityRelationships = append([]*proto.EntityRelationship{}, links...)
 }
 
 // AddKeystoneRelationship adds a relationship
 func (e *EntityRelationships) AddKeystoneRelationship(relationshipType, target string, meta map[string]string, since time.Time) {
+	e.ksEntityRemoveRelationships = removeRelationship(e.ksEntityRemoveRelationships, relationshipType, target)
 	e.ksEntityRelationships = append(e.ksEntityRelationships, &proto.EntityRelationship{
 		Relationship: &proto.Key{Key: relationshipType},
 		TargetId:     target,
@@ -44,3 +62,88 @@ func (e *EntityRelationships) AddKeystoneRelationship(relationshipType, target s
 		Since:        timestamppb.New(since),
 	})
 }
+
+// RemoveKeystoneRelationship removes a relationship
+func (e *EntityRelationships) RemoveKeystoneRelationship(relationshipType, target string) {
+	e.ksEntityRelationships = removeRelationship(e.ksEntityRelationships, relationshipType, target)
+	e.ksEntityRemoveRelationships = append(e.ksEntityRemoveRelationships, &proto.EntityRelationship{
+		Relationship: &proto.Key{Key: relationshipType},
+		TargetId:     target,
+	})
+}
+
+// ReplaceKeystoneRelationships replaces all relationships, removing any loaded relationships that are not provided
+func (e *EntityRelationships) ReplaceKeystoneRelationships(links []*proto.EntityRelationship) {
+	e.ksEntityRelationships = append([]*proto.EntityRelationship{}, links...)
+	e.ksEntityRemoveRelationships = []*proto.EntityRelationship{}
+	e.ksReplaceRelationships = true
+}
+
+// HydrateKeystoneRelationships sets the relationships loaded from keystone
+func (e *EntityRelationships) HydrateKeystoneRelationships(links []*proto.EntityRelationship) {
+	e.ksLoadedRelationships = append([]*proto.EntityRelationship{}, links...)
+	e.ksEntityRelationships = append([]*proto.EntityRelationship{}, links...)
+	e.ksEntityRemoveRelationships = []*proto.EntityRelationship{}
+	e.ksReplaceRelationships = false
+}
+
+// GetKeystoneRelationshipChanges returns the relationships to add and remove, compared to the loaded relationships
+func (e *EntityRelationships) GetKeystoneRelationshipChanges() (add, remove []*proto.EntityRelationship) {
+	loaded := make(map[string]bool, len(e.ksLoadedRelationships))
+	for _, r := range e.ksLoadedRelationships {
+		loaded[relationshipKey(r)] = true
+	}
+
+	current := make(map[string]bool, len(e.ksEntityRelationships))
+	for _, r := range e.ksEntityRelationships {
+		current[relationshipKey(r)] = true
+		if !loaded[relationshipKey(r)] {
+			add = append(add, r)
+		}
+	}
+
+	remove = append(remove, e.ksEntityRemoveRelationships...)
+	if e.ksReplaceRelationships {
+		for _, r := range e.ksLoadedRelationships {
+			if !current[relationshipKey(r)] {
+				remove = append(remove, r)
+			}
+		}
+	}
+	return add, remove
+}
+
+// commitKeystoneRelationships marks the relationship changes as sent, so they are loaded rather than staged
+func (e *EntityRelationships) commitKeystoneRelationships() {
+	add, remove := e.GetKeystoneRelationshipChanges()
+	removed := make(map[string]bool, len(remove))
+	for _, r := range remove {
+		removed[relationshipKey(r)] = true
+	}
+
+	var loaded []*proto.EntityRelationship
+	for _, r := range slices.Concat(e.ksLoadedRelationships, add) {
+		if !removed[relationshipKey(r)] {
+			loaded = append(loaded, r)
+		}
+	}
+	e.ksLoadedRelationships = loaded
+	e.ksEntityRelationships = append([]*proto.EntityRelationship{}, loaded...)
+	e.ksEntityRemoveRelationships = []*proto.EntityRelationship{}
+	e.ksReplaceRelationships = false
+}
+
+// relationshipKey identifies a relationship by type and target, as relationships added locally have no source
+func relationshipKey(r *proto.EntityRelationship) string {
+	return r.GetRelationship().GetKey() + ">" + r.GetTargetId()
+}
+
+func removeRelationship(links []*proto.EntityRelationship, relationshipType, target string) []*proto.EntityRelationship {
+	var keep []*proto.EntityRelationship
+	for _, r := range links {
+		if r.GetRelationship().GetKey() != relationshipType || r.GetTargetId() != target {
+			keep = append(keep, r)
+		}
+	}
+	return keep
+}
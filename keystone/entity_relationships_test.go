@@ -0,0 +1,68 @@
+package keystone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func loadedRelationship(relationshipType, target string) *proto.EntityRelationship {
+	return &proto.EntityRelationship{
+		Relationship: &proto.Key{Key: relationshipType, Source: &proto.VendorApp{VendorId: "vendor", AppId: "app"}},
+		TargetId:     target,
+	}
+}
+
+func TestEntityRelationships_AddExisting(t *testing.T) {
+	e := &EntityRelationships{}
+	e.HydrateKeystoneRelationships([]*proto.EntityRelationship{loadedRelationship("owner", "u1")})
+
+	e.AddKeystoneRelationship("owner", "u1", nil, time.Now())
+	add, remove := e.GetKeystoneRelationshipChanges()
+	if len(add) != 0 || len(remove) != 0 {
+		t.Error("Expected re-adding a loaded relationship to be a no-op, got", add, remove)
+	}
+}
+
+func TestEntityRelationships_ReplaceKeystoneRelationships(t *testing.T) {
+	e := &EntityRelationships{}
+	e.HydrateKeystoneRelationships([]*proto.EntityRelationship{
+		loadedRelationship("owner", "u1"),
+		loadedRelationship("member", "u2"),
+	})
+
+	links := []*proto.EntityRelationship{
+		{Relationship: &proto.Key{Key: "owner"}, TargetId: "u1"},
+		{Relationship: &proto.Key{Key: "member"}, TargetId: "u3"},
+	}
+	e.ReplaceKeystoneRelationships(links)
+	links[1] = &proto.EntityRelationship{Relationship: &proto.Key{Key: "member"}, TargetId: "u4"}
+
+	add, remove := e.GetKeystoneRelationshipChanges()
+	if len(add) != 1 || add[0].GetTargetId() != "u3" {
+		t.Error("Expected member u3 to be added, got", add)
+	}
+	if len(remove) != 1 || remove[0].GetTargetId() != "u2" {
+		t.Error("Expected member u2 to be removed, got", remove)
+	}
+}
+
+func TestEntityRelationships_Commit(t *testing.T) {
+	e := &EntityRelationships{}
+	e.HydrateKeystoneRelationships([]*proto.EntityRelationship{loadedRelationship("owner", "u1")})
+	e.AddKeystoneRelationship("member", "u2", nil, time.Now())
+	e.RemoveKeystoneRelationship("owner", "u1")
+	e.commitKeystoneRelationships()
+
+	add, remove := e.GetKeystoneRelationshipChanges()
+	if len(add) != 0 || len(remove) != 0 {
+		t.Error("Expected no changes after commit, got", add, remove)
+	}
+
+	e.ReplaceKeystoneRelationships(nil)
+	_, remove = e.GetKeystoneRelationshipChanges()
+	if len(remove) != 1 || remove[0].GetTargetId() != "u2" {
+		t.Error("Expected the committed member u2 to be removed, got", remove)
+	}
+}
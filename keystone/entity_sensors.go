@@ -1,6 +1,8 @@
 package keystone
 
 import (
+	"time"
+
 	"github.com/kubex/keystone-go/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -29,19 +31,25 @@ func (e *EntitySensors) GetKeystoneSensorMeasurements() []*proto.EntitySensorMea
 
 // AddKeystoneSensorMeasurement adds a Sensor measurement
 func (e *EntitySensors) AddKeystoneSensorMeasurement(sensor string, value float64) {
-	e.ksEntitySensorsMeasurements = append(e.ksEntitySensorsMeasurements, &proto.EntitySensorMeasurement{
-		Sensor: sensor,
-		Value:  value,
-		At:     timestamppb.Now(),
-	})
+	e.AddKeystoneSensorMeasurementAt(sensor, value, time.Now())
+}
+
+// AddKeystoneSensorMeasurementAt adds a Sensor measurement taken at the given time
+func (e *EntitySensors) AddKeystoneSensorMeasurementAt(sensor string, value float64, at time.Time) {
+	e.AddKeystoneSensorMeasurementWithDataAt(sensor, value, nil, at)
 }
 
 // AddKeystoneSensorMeasurementWithData adds a Sensor measurement
 func (e *EntitySensors) AddKeystoneSensorMeasurementWithData(sensor string, value float64, data map[string]string) {
+	e.AddKeystoneSensorMeasurementWithDataAt(sensor, value, data, time.Now())
+}
+
+// AddKeystoneSensorMeasurementWithDataAt adds a Sensor measurement taken at the given time
+func (e *EntitySensors) AddKeystoneSensorMeasurementWithDataAt(sensor string, value float64, data map[string]string, at time.Time) {
 	e.ksEntitySensorsMeasurements = append(e.ksEntitySensorsMeasurements, &proto.EntitySensorMeasurement{
 		Sensor: sensor,
 		Value:  value,
-		At:     timestamppb.Now(),
+		At:     timestamppb.New(at),
 		Data:   data,
 	})
 }
@@ -0,0 +1,231 @@
+package keystone
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Filter builds find options for the entity type T, checking properties, operators and values against its schema
+type Filter[T any] struct {
+	properties map[string]filterProperty
+	options    []FindOption
+	errs       []error
+	warnings   []string
+}
+
+type filterProperty struct {
+	name     string
+	dataType proto.Property_Type
+	indexed  bool
+}
+
+// NewFilter creates a filter builder for the entity type T
+func NewFilter[T any]() *Filter[T] {
+	f := &Filter[T]{properties: make(map[string]filterProperty)}
+	f.addProperties(reflect.TypeOf(new(T)).Elem(), "", "")
+	return f
+}
+
+// addProperties maps both the Go field path and the property name of each field to its property
+func (f *Filter[T]) addProperties(t reflect.Type, prefix, fieldPrefix string) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			f.addProperties(field.Type, prefix, fieldPrefix)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, prefix)
+		if !fOpt.isProperty() {
+			continue
+		}
+
+		if !supportedType(field.Type) {
+			f.addProperties(field.Type, fOpt.name+".", fieldPrefix+field.Name+".")
+			continue
+		}
+
+		// apply the tag options so personal data and verify only fields take the type they are stored as
+		protoField := &proto.Property{}
+		protoField.DataType, protoField.ExtendedType = getFieldType(field)
+		fOpt.applyTo(protoField)
+		prop := filterProperty{name: fOpt.name, dataType: protoField.GetDataType(), indexed: fOpt.indexed || fOpt.unique}
+		f.properties[fieldPrefix+field.Name] = prop
+		f.properties[fOpt.name] = prop
+	}
+}
+
+// Where filters on the field, which can be the Go field path e.g. Address.City or the property name
+func (f *Filter[T]) Where(field string, operator proto.Operator, values ...any) *Filter[T] {
+	prop, ok := f.properties[field]
+	if !ok {
+		f.errs = append(f.errs, fmt.Errorf("%s is not a property of %s", field, Type(new(T))))
+		return f
+	}
+
+	if !slices.Contains(filterOperators[prop.dataType], operator) {
+		f.errs = append(f.errs, fmt.Errorf("%s operator is not supported for %s property %s", operator, prop.dataType, prop.name))
+		return f
+	}
+
+	switch {
+	case operator == proto.Operator_Between && len(values) != 2:
+		f.errs = append(f.errs, fmt.Errorf("%s requires 2 values for %s, got %d", operator, prop.name, len(values)))
+		return f
+	case operator != proto.Operator_Between && operator != proto.Operator_In && operator != proto.Operator_NotIn && len(values) != 1:
+		f.errs = append(f.errs, fmt.Errorf("%s requires 1 value for %s, got %d", operator, prop.name, len(values)))
+		return f
+	case len(values) == 0:
+		f.errs = append(f.errs, fmt.Errorf("%s requires at least 1 value for %s", operator, prop.name))
+		return f
+	}
+
+	for _, value := range values {
+		if !filterValueCompatible(prop.dataType, value) {
+			f.errs = append(f.errs, fmt.Errorf("%T value cannot be used to filter %s property %s", value, prop.dataType, prop.name))
+			return f
+		}
+	}
+
+	if !prop.indexed {
+		f.warnings = append(f.warnings, fmt.Sprintf("filtering on %s which is not indexed", prop.name))
+	}
+
+	encoded := make([]*proto.Value, 0, len(values))
+	for _, value := range values {
+		encoded = append(encoded, filterValue(prop.dataType, value))
+	}
+	f.options = append(f.options, propertyFilter{key: prop.name, values: encoded, operator: operator})
+	return f
+}
+
+// Equals filters on the field equaling the value
+func (f *Filter[T]) Equals(field string, value any) *Filter[T] {
+	return f.Where(field, proto.Operator_Equal, value)
+}
+
+// In filters on the field being one of the values
+func (f *Filter[T]) In(field string, values ...any) *Filter[T] {
+	return f.Where(field, proto.Operator_In, values...)
+}
+
+// Between filters on the field being between from and to
+func (f *Filter[T]) Between(field string, from, to any) *Filter[T] {
+	return f.Where(field, proto.Operator_Between, from, to)
+}
+
+// Warnings returns the filters that may be slow or rejected by keystone, such as filters on properties not indexed
+func (f *Filter[T]) Warnings() []string { return f.warnings }
+
+// Options returns the find options, or the errors found while building the filter
+func (f *Filter[T]) Options() ([]FindOption, error) {
+	if len(f.errs) > 0 {
+		return nil, errors.Join(f.errs...)
+	}
+	return f.options, nil
+}
+
+var comparableOperators = []proto.Operator{
+	proto.Operator_Equal, proto.Operator_NotEqual,
+	proto.Operator_GreaterThan, proto.Operator_GreaterThanOrEqual,
+	proto.Operator_LessThan, proto.Operator_LessThanOrEqual,
+	proto.Operator_In, proto.Operator_NotIn, proto.Operator_Between,
+}
+
+var textOperators = []proto.Operator{
+	proto.Operator_Equal, proto.Operator_NotEqual,
+	proto.Operator_Contains, proto.Operator_NotContains,
+	proto.Operator_StartsWith, proto.Operator_EndsWith,
+	proto.Operator_In, proto.Operator_NotIn,
+}
+
+var listOperators = []proto.Operator{
+	proto.Operator_Equal, proto.Operator_NotEqual,
+	proto.Operator_Contains, proto.Operator_NotContains,
+	proto.Operator_In, proto.Operator_NotIn,
+}
+
+// filterOperators are the operators supported by each property type
+var filterOperators = map[proto.Property_Type][]proto.Operator{
+	proto.Property_Text:       textOperators,
+	proto.Property_SecureText: textOperators,
+	proto.Property_VerifyText: {proto.Operator_Equal, proto.Operator_NotEqual},
+	proto.Property_Number:     comparableOperators,
+	proto.Property_Float:      comparableOperators,
+	proto.Property_Time:       comparableOperators,
+	proto.Property_Amount:     comparableOperators,
+	proto.Property_Boolean:    {proto.Operator_Equal, proto.Operator_NotEqual},
+	proto.Property_Strings:    listOperators,
+	proto.Property_StringSet:  listOperators,
+	proto.Property_Ints:       listOperators,
+	proto.Property_IntSet:     listOperators,
+	proto.Property_KeyValue:   {proto.Operator_Contains, proto.Operator_NotContains},
+}
+
+// filterValueCompatible returns true when the Go value can be encoded for the property type
+func filterValueCompatible(dataType proto.Property_Type, value any) bool {
+	switch value.(type) {
+	case Amount, *Amount:
+		return dataType == proto.Property_Amount
+	case StringSet, *StringSet:
+		return dataType == proto.Property_Strings || dataType == proto.Property_StringSet
+	case IntSet, *IntSet:
+		return dataType == proto.Property_Ints || dataType == proto.Property_IntSet
+	case time.Time, *time.Time, *timestamppb.Timestamp:
+		return dataType == proto.Property_Time
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch dataType {
+		case proto.Property_Text, proto.Property_SecureText, proto.Property_VerifyText,
+			proto.Property_Strings, proto.Property_StringSet, proto.Property_KeyValue:
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// amounts need a currency, so are filtered with Amount values
+		switch dataType {
+		case proto.Property_Number, proto.Property_Float, proto.Property_Ints, proto.Property_IntSet:
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		return dataType == proto.Property_Float
+	case reflect.Bool:
+		return dataType == proto.Property_Boolean
+	}
+	return false
+}
+
+// filterValue encodes a compatible value, converting whole numbers for float properties
+func filterValue(dataType proto.Property_Type, value any) *proto.Value {
+	encoded := valueFromAny(value)
+	if dataType == proto.Property_Float && encoded.GetFloat() == 0 && encoded.GetInt() != 0 {
+		return &proto.Value{Float: float64(encoded.GetInt())}
+	}
+	return encoded
+}
@@ -0,0 +1,120 @@
+package keystone
+
+import (
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testFilterAddress struct {
+	City string `keystone:",indexed"`
+}
+
+type testFilterEntity struct {
+	BaseEntity
+	Name    string `keystone:",indexed"`
+	Notes   string
+	Score   int64 `keystone:",indexed"`
+	Ratio   float32
+	Total   Amount
+	Tags    StringSet
+	Address testFilterAddress
+}
+
+func TestFilterBuilder(t *testing.T) {
+	f := NewFilter[testFilterEntity]().
+		Equals("Name", "Ant").
+		Where("score", proto.Operator_GreaterThan, uint32(5)).
+		Equals("Address.City", "London").
+		Where("Notes", proto.Operator_Contains, "urgent")
+
+	options, err := f.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fReq := &filterRequest{}
+	for _, opt := range options {
+		opt.Apply(fReq)
+	}
+	if len(fReq.Filters) != 4 {
+		t.Fatal("Expected 4 filters, got", len(fReq.Filters))
+	}
+	if fReq.Filters[1].GetValues()[0].GetInt() != 5 {
+		t.Error("Expected uint value to be encoded, got", fReq.Filters[1].GetValues())
+	}
+	if fReq.Filters[2].GetProperty() != "address.city" {
+		t.Error("Expected nested field to resolve to address.city, got", fReq.Filters[2].GetProperty())
+	}
+	if len(f.Warnings()) != 1 {
+		t.Error("Expected a warning for the notes property not being indexed, got", f.Warnings())
+	}
+
+	invalid := []*Filter[testFilterEntity]{
+		NewFilter[testFilterEntity]().Equals("Missing", "x"),
+		NewFilter[testFilterEntity]().Where("Name", proto.Operator_GreaterThan, 1),
+		NewFilter[testFilterEntity]().Equals("Score", "high"),
+		NewFilter[testFilterEntity]().Where("Score", proto.Operator_Between, 1),
+	}
+	for i, filter := range invalid {
+		if _, err := filter.Options(); err == nil {
+			t.Errorf("Expected filter %d to be invalid", i)
+		}
+	}
+}
+
+func TestValueFromAny(t *testing.T) {
+	tags := StringSet{}
+	tags.ReplaceWith("a")
+
+	if v := valueFromAny(NewAmount("USD", 150)); v.GetText() != "USD" || v.GetInt() != 150 {
+		t.Error("Expected amount to be encoded, got", v)
+	}
+	if v := valueFromAny(float32(1.5)); v.GetFloat() != 1.5 {
+		t.Error("Expected float32 to be encoded, got", v)
+	}
+	if v := valueFromAny(uint(7)); v.GetInt() != 7 {
+		t.Error("Expected uint to be encoded, got", v)
+	}
+	if v := valueFromAny(tags); len(v.GetArray().GetStrings()) != 1 {
+		t.Error("Expected string set to be encoded, got", v)
+	}
+}
+
+type testFilterTypedEntity struct {
+	BaseEntity
+	Ratio float64 `keystone:",indexed"`
+	Total Amount  `keystone:",indexed"`
+	Email string  `keystone:",pii,indexed"`
+}
+
+func TestFilterBuilderTypes(t *testing.T) {
+	f := NewFilter[testFilterTypedEntity]().
+		Where("Ratio", proto.Operator_GreaterThan, 2).
+		Where("Total", proto.Operator_GreaterThan, NewAmount("GBP", 500))
+
+	options, err := f.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fReq := &filterRequest{}
+	for _, opt := range options {
+		opt.Apply(fReq)
+	}
+	if v := fReq.Filters[0].GetValues()[0]; v.GetFloat() != 2 || v.GetInt() != 0 {
+		t.Error("Expected a whole number to be encoded as a float for a float property, got", v)
+	}
+	if v := fReq.Filters[1].GetValues()[0]; v.GetText() != "GBP" || v.GetInt() != 500 {
+		t.Error("Expected the amount to be encoded with its currency, got", v)
+	}
+
+	if _, err := NewFilter[testFilterTypedEntity]().Where("Total", proto.Operator_GreaterThan, 500).Options(); err == nil {
+		t.Error("Expected a whole number without a currency to be rejected for an amount")
+	}
+	if _, err := NewFilter[testFilterTypedEntity]().Where("Email", proto.Operator_StartsWith, "a").Options(); err != nil {
+		t.Error("Expected text operators for a personal data property, got", err)
+	}
+	if NewFilter[testFilterTypedEntity]().properties["email"].dataType != proto.Property_SecureText {
+		t.Error("Expected personal data to be filtered as secure text")
+	}
+}
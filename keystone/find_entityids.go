@@ -0,0 +1,14 @@
+package keystone
+
+type withEntityIDs struct {
+	entityIDs []string
+}
+
+func (f withEntityIDs) Apply(config *filterRequest) {
+	config.EntityIDs = append(config.EntityIDs, f.entityIDs...)
+}
+
+// WithEntityIDs limits the find to the given entity IDs
+func WithEntityIDs(entityIDs ...string) FindOption {
+	return withEntityIDs{entityIDs: entityIDs}
+}
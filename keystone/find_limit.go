@@ -13,3 +13,19 @@ func (f withLimit) Apply(config *filterRequest) {
 func Limit(perPage, pageNumber int32) FindOption {
 	return withLimit{perPage: perPage, pageNumber: pageNumber}
 }
+
+type withCursor struct {
+	perPage int32
+	afterID string
+}
+
+func (f withCursor) Apply(config *filterRequest) {
+	config.PerPage = f.perPage
+	config.PageNumber = 0
+	config.AfterID = f.afterID
+}
+
+// After requests a page of perPage entities following the entity with afterID
+func After(perPage int32, afterID string) FindOption {
+	return withCursor{perPage: perPage, afterID: afterID}
+}
@@ -91,6 +91,34 @@ func (f propertyFilter) Apply(config *filterRequest) {
 }
 
 func valueFromAny(value any) *proto.Value {
+	switch val := value.(type) {
+	case Amount:
+		return val.ToProtoValue()
+	case *Amount:
+		if val == nil {
+			return nil
+		}
+		return val.ToProtoValue()
+	case StringSet:
+		return &proto.Value{Array: &proto.RepeatedValue{Strings: val.Values()}}
+	case *StringSet:
+		if val == nil {
+			return nil
+		}
+		return &proto.Value{Array: &proto.RepeatedValue{Strings: val.Values()}}
+	case IntSet:
+		return &proto.Value{Array: &proto.RepeatedValue{Ints: val.Values()}}
+	case *IntSet:
+		if val == nil {
+			return nil
+		}
+		return &proto.Value{Array: &proto.RepeatedValue{Ints: val.Values()}}
+	case time.Time:
+		return &proto.Value{Time: timestamppb.New(val)}
+	case *timestamppb.Timestamp:
+		return &proto.Value{Time: val}
+	}
+
 	v := reflect.ValueOf(value)
 
 	for v.Kind() == reflect.Ptr {
@@ -100,22 +128,21 @@ func valueFromAny(value any) *proto.Value {
 		v = v.Elem()
 	}
 
-	switch v.Kind().String() {
-	case "string":
+	switch v.Kind() {
+	case reflect.String:
 		return &proto.Value{Text: v.String()}
-	case "int", "int32", "int64":
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return &proto.Value{Int: v.Int()}
-	case "bool":
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &proto.Value{Int: int64(v.Uint())}
+	case reflect.Bool:
 		return &proto.Value{Bool: v.Bool()}
-	case "float64":
+	case reflect.Float32, reflect.Float64:
 		return &proto.Value{Float: v.Float()}
 	}
 
-	switch v.Type().String() {
-	case "time.Time":
-		return &proto.Value{Time: timestamppb.New(value.(time.Time))}
-	case "timestamppb.Timestamp":
-		return &proto.Value{Time: value.(*timestamppb.Timestamp)}
+	if v.Type() == typeOfTime {
+		return &proto.Value{Time: timestamppb.New(v.Interface().(time.Time))}
 	}
 	return proto.NewValue()
 }
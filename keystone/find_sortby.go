@@ -1,15 +1,71 @@
 package keystone
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
 type sortBy struct {
 	property   string
 	descending bool
 }
 
 func (f sortBy) Apply(config *filterRequest) {
-	config.SortProperty = f.property
-	config.SortDescending = f.descending
+	config.Sort = append(config.Sort, &proto.PropertySort{Property: f.property, Descending: f.descending})
+}
+
+// SortBy sorts by each property in order, prefix a property with - to sort descending e.g. SortBy("-created", "name")
+// Multiple SortBy options are applied in order
+func SortBy(properties ...string) FindOption {
+	order := make(sortOrder, 0, len(properties))
+	for _, property := range properties {
+		order = append(order, sortBy{property: strings.TrimPrefix(property, "-"), descending: strings.HasPrefix(property, "-")})
+	}
+	return order
 }
 
-func SortBy(property string, descending bool) FindOption {
+// SortByDirection sorts by the property in the given direction
+func SortByDirection(property string, descending bool) FindOption {
 	return sortBy{property: property, descending: descending}
 }
+
+type sortOrder []sortBy
+
+func (f sortOrder) Apply(config *filterRequest) {
+	for _, s := range f {
+		s.Apply(config)
+	}
+}
+
+// entitySortable returns true for fields of the entity itself, which can be sorted on without being defined in the schema
+func entitySortable(name string) bool {
+	fields := (&proto.Entity{}).ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if snakeCase(string(fields.Get(i).Name())) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSort checks sort properties exist and are indexed in the schema
+func validateSort(schema *proto.Schema, sort []*proto.PropertySort) error {
+	for _, s := range sort {
+		name := s.GetProperty()
+		if strings.HasPrefix(name, "_") || entitySortable(name) {
+			continue
+		}
+
+		idx := slices.IndexFunc(schema.GetProperties(), func(p *proto.Property) bool { return p.GetName() == name })
+		if idx < 0 {
+			return fmt.Errorf("cannot sort by %s, property is not defined on %s", name, schema.GetType())
+		}
+		if !slices.Contains(schema.GetProperties()[idx].GetOptions(), proto.Property_Indexed) {
+			return fmt.Errorf("cannot sort by %s, property is not indexed on %s", name, schema.GetType())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package keystone
+
+import (
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestSort(t *testing.T) {
+	fReq := &filterRequest{}
+	SortByDirection("priority", true).Apply(fReq)
+	SortBy("-created", "name").Apply(fReq)
+
+	if len(fReq.Sort) != 3 {
+		t.Fatal("Expected 3 sort properties, got", len(fReq.Sort))
+	}
+	expect := []struct {
+		property   string
+		descending bool
+	}{{"priority", true}, {"created", true}, {"name", false}}
+	for i, e := range expect {
+		if fReq.Sort[i].GetProperty() != e.property || fReq.Sort[i].GetDescending() != e.descending {
+			t.Errorf("Expected sort %d to be %v, got %v", i, e, fReq.Sort[i])
+		}
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	schema := &proto.Schema{Type: "test", Properties: []*proto.Property{
+		{Name: "name", Options: []proto.Property_Option{proto.Property_Indexed}},
+		{Name: "notes"},
+	}}
+
+	if err := validateSort(schema, []*proto.PropertySort{{Property: "created"}, {Property: "last_update"}, {Property: "name"}}); err != nil {
+		t.Error("Expected indexed sort to be valid, got", err)
+	}
+	if err := validateSort(schema, []*proto.PropertySort{{Property: "notes"}}); err == nil {
+		t.Error("Expected sort by non indexed property to fail")
+	}
+	if err := validateSort(schema, []*proto.PropertySort{{Property: "missing"}}); err == nil {
+		t.Error("Expected sort by unknown property to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/kubex/keystone-go/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// DefaultBatchSize is the number of entity IDs requested in a single find by GetMany, the Loader and related entity loading
+const DefaultBatchSize = 100
+
+// SetBatchSize sets the number of entity IDs requested in a single find, zero restores the default
+func (c *Connection) SetBatchSize(size int) {
+	c.findBatchSize.Store(int64(size))
+}
+
+func (c *Connection) batchSize() int {
+	if size := int(c.findBatchSize.Load()); size > 0 {
+		return size
+	}
+	return DefaultBatchSize
+}
+
+// preparedView is a retrieve option applying a view built by prepareView
+type preparedView struct{ view *proto.EntityView }
+
+func (l preparedView) Apply(config *proto.EntityView) { protobuf.Merge(config, l.view) }
+
+// GetMany retrieves the entities with the given IDs, appending them to dstSlicePtr in the order of entityIDs
+// The IDs of entities that could not be found are returned
+func (a *Actor) GetMany(ctx context.Context, entityIDs []string, dstSlicePtr any, retrieve ...RetrieveOption) ([]string, error) {
+	dstT := reflect.TypeOf(dstSlicePtr)
+	if dstT == nil || dstT.Kind() != reflect.Pointer || dstT.Elem().Kind() != reflect.Slice {
+		return nil, errors.New("dst must be a slice pointer")
+	}
+
+	elementType := dstT.Elem().Elem()
+	pointer := elementType.Kind() == reflect.Pointer
+	if pointer {
+		elementType = elementType.Elem()
+	}
+
+	view := &proto.EntityView{}
+	RetrieveOptions(retrieve...).Apply(view)
+	schema, err := a.prepareView(view, reflect.New(elementType).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make([]string, 0, len(entityIDs))
+	found := make(map[string]*proto.EntityResponse, len(entityIDs))
+	for _, id := range entityIDs {
+		if _, ok := found[id]; !ok {
+			found[id] = nil
+			unique = append(unique, id)
+		}
+	}
+
+	batchSize := a.connection.batchSize()
+	for start := 0; start < len(unique); start += batchSize {
+		batch := unique[start:min(start+batchSize, len(unique))]
+		resp, err := a.find(ctx, schema.GetType(), preparedView{view: view}, WithEntityIDs(batch...))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.GetEntities() {
+			if _, requested := found[r.GetEntity().GetEntityId()]; requested {
+				found[r.GetEntity().GetEntityId()] = r
+			}
+		}
+	}
+
+	var missing []string
+	reported := make(map[string]bool)
+	var loaded []*proto.EntityResponse
+	var loadedDsts []reflect.Value
+	for _, id := range entityIDs {
+		r := found[id]
+		if r == nil {
+			if !reported[id] {
+				reported[id] = true
+				missing = append(missing, id)
+			}
+			continue
+		}
+
+		dstEle := reflect.New(elementType)
+		if err := unmarshal(ctx, a, r, dstEle.Interface()); err != nil {
+			return missing, err
+		}
+		loaded = append(loaded, r)
+		loadedDsts = append(loadedDsts, dstEle)
+	}
+
+	if err := a.loadRelations(ctx, loaded, loadedDsts, 0); err != nil {
+		return missing, err
+	}
+
+	dst := reflect.ValueOf(dstSlicePtr).Elem()
+	for _, dstEle := range loadedDsts {
+		if pointer {
+			dst.Set(reflect.Append(dst, dstEle))
+		} else {
+			dst.Set(reflect.Append(dst, dstEle.Elem()))
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,51 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestActor_GetMany(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	stored := map[string]string{"a": "Ant", "b": "Bee", "c": "Cat"}
+	requests := 0
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		requests++
+		resp := &proto.FindResponse{}
+		for _, id := range req.GetEntityIds() {
+			if name, ok := stored[id]; ok {
+				resp.Entities = append(resp.Entities, &proto.EntityResponse{
+					Entity:     &proto.Entity{EntityId: id},
+					Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: name}}},
+				})
+			}
+		}
+		return resp, nil
+	}
+
+	conn.SetBatchSize(2)
+
+	actor := conn.Actor("workspace", "", "", "")
+	var result []*testTypedEntity
+	missing, err := actor.GetMany(context.Background(), []string{"c", "x", "a", "b"}, &result, WithProperties("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Error("Expected 2 batched requests, got", requests)
+	}
+	if len(missing) != 1 || missing[0] != "x" {
+		t.Error("Expected x to be missing, got", missing)
+	}
+	if len(result) != 3 || result[0].Name != "Cat" || result[1].Name != "Ant" || result[2].Name != "Bee" {
+		t.Error("Expected entities in requested order", result)
+	}
+}
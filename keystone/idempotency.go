@@ -0,0 +1,175 @@
+package keystone
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// IdempotencyKeyHeader is the request metadata key carrying the idempotency key
+const IdempotencyKeyHeader = "keystone-idempotency-key"
+
+// DefaultIdempotencyWindow is how long a successful idempotency key is remembered
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// DefaultIdempotencyLimit is the number of successful idempotency keys remembered by a connection
+const DefaultIdempotencyLimit = 10000
+
+type idempotencyKey struct{ key string }
+
+func (m idempotencyKey) apply(*proto.MutateRequest)             {}
+func (m idempotencyKey) applySettings(settings *mutateSettings) { settings.idempotencyKey = m.key }
+
+// IdempotencyKey sends the mutation with the given key, a key already confirmed within the idempotency window is not resent
+func IdempotencyKey(key string) MutateOption {
+	return idempotencyKey{key: key}
+}
+
+type retry struct {
+	attempts int
+	backoff  time.Duration
+}
+
+func (m retry) apply(*proto.MutateRequest) {}
+func (m retry) applySettings(settings *mutateSettings) {
+	settings.retries = m.attempts
+	settings.retryBackoff = m.backoff
+}
+
+// Retry retries the mutation on unavailable or timed out errors, generating an idempotency key if one is not set
+func Retry(attempts int, backoff time.Duration) MutateOption {
+	return retry{attempts: attempts, backoff: backoff}
+}
+
+// SetIdempotencyWindow sets how long successful idempotency keys are remembered
+func (c *Connection) SetIdempotencyWindow(window time.Duration) {
+	c.idempotency.setWindow(window)
+}
+
+// SetIdempotencyLimit sets how many successful idempotency keys are remembered, dropping the oldest beyond it
+func (c *Connection) SetIdempotencyLimit(limit int) {
+	c.idempotency.setLimit(limit)
+}
+
+// sendIdempotent sends the mutation, applying idempotency and retry settings
+// Idempotency keys are remembered per workspace
+func (c *Connection) sendIdempotent(ctx context.Context, workspaceID string, settings mutateSettings, send func(ctx context.Context) (*proto.MutateResponse, error)) (*proto.MutateResponse, error) {
+	key := settings.idempotencyKey
+	if key == "" && settings.retries > 0 {
+		key = newIdempotencyKey()
+	}
+
+	if key != "" {
+		if resp, ok := c.idempotency.get(workspaceID, key); ok {
+			return resp, nil
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, IdempotencyKeyHeader, key)
+	}
+
+	resp, err := send(ctx)
+	for attempt := 1; err != nil && attempt <= settings.retries && retryable(err); attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(settings.retryBackoff * time.Duration(attempt)):
+		}
+		resp, err = send(ctx)
+	}
+
+	if key != "" && err == nil && resp.GetSuccess() {
+		c.idempotency.set(workspaceID, key, resp)
+	}
+	return resp, err
+}
+
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type idempotencyEntry struct {
+	key     string
+	resp    *proto.MutateResponse
+	expires time.Time
+}
+
+// idempotencyCache remembers the responses of successful mutations by workspace and idempotency key
+type idempotencyCache struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	entries map[string]*list.Element
+	order   *list.List // newest first
+}
+
+func newIdempotencyCache(window time.Duration, limit int) *idempotencyCache {
+	return &idempotencyCache{window: window, limit: limit, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *idempotencyCache) setWindow(window time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.window = window
+}
+
+func (c *idempotencyCache) setLimit(limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limit = limit
+	c.prune(time.Now())
+}
+
+func (c *idempotencyCache) get(workspaceID, key string) (*proto.MutateResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[workspaceID+"\x00"+key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*idempotencyEntry)
+	if time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+func (c *idempotencyCache) set(workspaceID, key string, resp *proto.MutateResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	key = workspaceID + "\x00" + key
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+	}
+	c.entries[key] = c.order.PushFront(&idempotencyEntry{key: key, resp: resp, expires: now.Add(c.window)})
+	c.prune(now)
+}
+
+// prune drops expired entries and the oldest entries beyond the limit
+func (c *idempotencyCache) prune(now time.Time) {
+	for el := c.order.Back(); el != nil; el = c.order.Back() {
+		entry := el.Value.(*idempotencyEntry)
+		if c.order.Len() <= c.limit && !now.After(entry.expires) {
+			return
+		}
+		c.order.Remove(el)
+		delete(c.entries, entry.key)
+	}
+}
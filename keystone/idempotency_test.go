@@ -0,0 +1,51 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConnection_sendIdempotent(t *testing.T) {
+	c := &Connection{idempotency: newIdempotencyCache(DefaultIdempotencyWindow, DefaultIdempotencyLimit)}
+	calls := 0
+	send := func(context.Context) (*proto.MutateResponse, error) {
+		calls++
+		if calls == 1 {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+		return &proto.MutateResponse{Success: true, EntityId: "abc"}, nil
+	}
+
+	settings := getMutateSettings([]MutateOption{IdempotencyKey("key"), Retry(2, 0)})
+	for i := 0; i < 2; i++ {
+		resp, err := c.sendIdempotent(context.Background(), "workspace", settings, send)
+		if err != nil || resp.GetEntityId() != "abc" {
+			t.Fatal("Expected success, got", resp, err)
+		}
+	}
+
+	if calls != 2 {
+		t.Error("Expected 2 calls (one retry, one deduplicated), got", calls)
+	}
+}
+
+func TestIdempotencyCache_Scope(t *testing.T) {
+	c := newIdempotencyCache(DefaultIdempotencyWindow, 2)
+	c.set("one", "key", &proto.MutateResponse{EntityId: "a"})
+	if _, ok := c.get("two", "key"); ok {
+		t.Error("Expected keys to be scoped to the workspace")
+	}
+
+	c.set("one", "key2", &proto.MutateResponse{EntityId: "b"})
+	c.set("one", "key3", &proto.MutateResponse{EntityId: "c"})
+	if _, ok := c.get("one", "key"); ok {
+		t.Error("Expected the oldest key to be dropped beyond the limit")
+	}
+	if resp, ok := c.get("one", "key3"); !ok || resp.GetEntityId() != "c" {
+		t.Error("Expected the newest key to be kept, got", resp)
+	}
+}
@@ -0,0 +1,198 @@
+package keystone
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// DefaultLoaderWait is how long a loader collects GetByID calls before sending them as a single find
+const DefaultLoaderWait = 2 * time.Millisecond
+
+// Loader coalesces concurrent GetByID calls into batched finds, it is intended to live for a single request
+type Loader struct {
+	actor   *Actor
+	wait    time.Duration
+	mu      sync.Mutex
+	batches map[string]*loaderBatch
+	calls   map[string]*loaderCall
+}
+
+type loaderBatch struct {
+	entityType string
+	retrieve   RetrieveOption
+	calls      map[string]*loaderCall
+	ctx        context.Context
+	timer      *time.Timer
+}
+
+type loaderCall struct {
+	key     string
+	request *proto.EntityRequest
+	done    chan struct{}
+	resp    *proto.EntityResponse
+	err     error
+}
+
+// Loader returns a loader batching GetByID calls made within wait of each other, wait defaults to DefaultLoaderWait
+func (a *Actor) Loader(wait time.Duration) *Loader {
+	if wait <= 0 {
+		wait = DefaultLoaderWait
+	}
+	return &Loader{actor: a, wait: wait, batches: make(map[string]*loaderBatch), calls: make(map[string]*loaderCall)}
+}
+
+// GetByID retrieves the entity into dst, sharing a single find with other calls for the same type and view
+// Entities are read through the connection cache, and identical successful calls are only requested once for the lifetime of the loader
+func (l *Loader) GetByID(ctx context.Context, entityID string, dst interface{}, retrieve ...RetrieveOption) error {
+	view := &proto.EntityView{}
+	useCache := true
+	for _, rOpt := range retrieve {
+		if _, ok := rOpt.(skipCache); ok {
+			useCache = false
+		}
+		rOpt.Apply(view)
+	}
+	schema, err := l.actor.prepareView(view, dst)
+	if err != nil {
+		return err
+	}
+
+	viewKey, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(view)
+	if err != nil {
+		return err
+	}
+	request := &proto.EntityRequest{
+		Authorization: l.actor.Authorization(),
+		EntityId:      entityID,
+		Schema:        &proto.Key{Key: schema.GetType(), Source: l.actor.Authorization().GetSource()},
+		View:          view,
+	}
+	batchKey := schema.GetType() + "\x00" + string(viewKey)
+	call := l.enqueue(ctx, batchKey, request, useCache)
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if call.err != nil {
+		return call.err
+	}
+
+	if err := unmarshal(ctx, l.actor, call.resp, dst); err != nil {
+		return err
+	}
+	return l.actor.loadRelations(ctx, []*proto.EntityResponse{call.resp}, []reflect.Value{reflect.ValueOf(dst)}, 0)
+}
+
+// enqueue returns the existing call for the entity, completes it from the cache, or adds it to the pending batch
+func (l *Loader) enqueue(ctx context.Context, batchKey string, request *proto.EntityRequest, useCache bool) *loaderCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entityID := request.GetEntityId()
+	callKey := batchKey + "\x00" + entityID
+	if call, ok := l.calls[callKey]; ok {
+		return call
+	}
+
+	call := &loaderCall{key: callKey, request: request, done: make(chan struct{})}
+	l.calls[callKey] = call
+
+	if cache := l.actor.connection.cache; cache != nil && useCache {
+		if key, _, ok := cacheKeys(request); ok {
+			if resp, err, ok := cache.get(key); ok {
+				l.complete(call, resp, err)
+				return call
+			}
+		}
+	}
+
+	batch, ok := l.batches[batchKey]
+	if !ok {
+		batch = &loaderBatch{
+			entityType: request.GetSchema().GetKey(),
+			retrieve:   preparedView{request.GetView()},
+			calls:      make(map[string]*loaderCall),
+			ctx:        context.WithoutCancel(ctx),
+		}
+		l.batches[batchKey] = batch
+		batch.timer = time.AfterFunc(l.wait, func() { l.dispatch(batchKey, batch) })
+	}
+	batch.calls[entityID] = call
+
+	if len(batch.calls) >= l.actor.connection.batchSize() {
+		batch.timer.Stop()
+		delete(l.batches, batchKey)
+		go l.send(batch)
+	}
+	return call
+}
+
+// dispatch sends the batch when it is still pending, a batch already sent for reaching the batch size is ignored
+func (l *Loader) dispatch(batchKey string, batch *loaderBatch) {
+	l.mu.Lock()
+	pending := l.batches[batchKey] == batch
+	if pending {
+		delete(l.batches, batchKey)
+	}
+	l.mu.Unlock()
+	if pending {
+		l.send(batch)
+	}
+}
+
+// send finds all entities in the batch, completing each call with its entity or a not found error
+func (l *Loader) send(batch *loaderBatch) {
+	entityIDs := make([]string, 0, len(batch.calls))
+	for entityID := range batch.calls {
+		entityIDs = append(entityIDs, entityID)
+	}
+
+	cache := l.actor.connection.cache
+	var generation uint64
+	if cache != nil {
+		generation = cache.currentGeneration()
+	}
+
+	resp, err := l.actor.find(batch.ctx, batch.entityType, batch.retrieve, WithEntityIDs(entityIDs...))
+	found := make(map[string]*proto.EntityResponse, len(entityIDs))
+	if err == nil {
+		for _, entity := range resp.GetEntities() {
+			if _, ok := found[entity.GetEntity().GetEntityId()]; !ok {
+				found[entity.GetEntity().GetEntityId()] = entity
+			}
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for entityID, call := range batch.calls {
+		callErr := err
+		if callErr == nil && found[entityID] == nil {
+			callErr = status.Errorf(codes.NotFound, "entity %s not found", entityID)
+		}
+		if cache != nil && (callErr == nil || status.Code(callErr) == codes.NotFound) {
+			if key, entityKey, ok := cacheKeys(call.request); ok {
+				cache.set(key, entityKey, generation, found[entityID], callErr)
+			}
+		}
+		l.complete(call, found[entityID], callErr)
+	}
+}
+
+// complete finishes the call, forgetting failed calls so they are retried by the next GetByID, l.mu must be held
+func (l *Loader) complete(call *loaderCall, resp *proto.EntityResponse, err error) {
+	call.resp, call.err = resp, err
+	if err != nil {
+		delete(l.calls, call.key)
+	}
+	close(call.done)
+}
@@ -0,0 +1,121 @@
+package keystone
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoader(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	var finds, requested atomic.Int32
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		finds.Add(1)
+		requested.Add(int32(len(req.GetEntityIds())))
+		resp := &proto.FindResponse{}
+		for _, id := range req.GetEntityIds() {
+			if id != "missing" {
+				resp.Entities = append(resp.Entities, &proto.EntityResponse{
+					Entity:     &proto.Entity{EntityId: id},
+					Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "name-" + id}}},
+				})
+			}
+		}
+		return resp, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+
+	loader := actor.Loader(20 * time.Millisecond)
+	ids := []string{"a", "b", "a", "c", "missing"}
+	results := make([]*testTypedEntity, len(ids))
+	errs := make([]error, len(ids))
+
+	wg := sync.WaitGroup{}
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			results[i] = &testTypedEntity{}
+			errs[i] = loader.GetByID(context.Background(), id, results[i], WithProperties("name"))
+		}(i, id)
+	}
+	wg.Wait()
+
+	if finds.Load() != 1 || requested.Load() != 4 {
+		t.Errorf("Expected one find for 4 unique IDs, got %d finds for %d IDs", finds.Load(), requested.Load())
+	}
+	for i, id := range ids {
+		if id == "missing" {
+			if status.Code(errs[i]) != codes.NotFound {
+				t.Error("Expected not found for missing entity, got", errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Error(errs[i])
+		} else if results[i].Name != "name-"+id {
+			t.Errorf("Expected name-%s, got %s", id, results[i].Name)
+		}
+	}
+}
+
+func TestLoader_RetryAndCache(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	var finds atomic.Int32
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		if finds.Add(1) == 1 {
+			return nil, status.Error(codes.Unavailable, "down")
+		}
+		resp := &proto.FindResponse{}
+		for _, id := range req.GetEntityIds() {
+			resp.Entities = append(resp.Entities, &proto.EntityResponse{
+				Entity:     &proto.Entity{EntityId: id},
+				Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "name-" + id}}},
+			})
+		}
+		return resp, nil
+	}
+
+	conn.EnableCache(CacheConfig{})
+	actor := conn.Actor("workspace", "", "", "")
+	ctx := context.Background()
+	loader := actor.Loader(time.Millisecond)
+
+	if err := loader.GetByID(ctx, "a", &testTypedEntity{}, WithProperties("name")); status.Code(err) != codes.Unavailable {
+		t.Fatal("Expected the first find to fail, got", err)
+	}
+	result := &testTypedEntity{}
+	if err := loader.GetByID(ctx, "a", result, WithProperties("name")); err != nil {
+		t.Fatal("Expected a failed call to be retried, got", err)
+	}
+	if result.Name != "name-a" {
+		t.Error("Unexpected name", result.Name)
+	}
+
+	cached := &testTypedEntity{}
+	if err := actor.Loader(time.Millisecond).GetByID(ctx, "a", cached, WithProperties("name")); err != nil {
+		t.Fatal(err)
+	}
+	if finds.Load() != 2 || cached.Name != "name-a" {
+		t.Error("Expected a new loader to read the entity from the cache, got", finds.Load(), "finds")
+	}
+}
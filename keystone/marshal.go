@@ -58,8 +58,8 @@ func (p *PropertyEncoder) fieldsToProperties(value reflect.Value, t reflect.Type
 		}
 
 		fOpt := getFieldOptions(field, prefix)
-		if fOpt.name == "" {
-			// Skip fields with no name, for desired exclusions (Marked with -)
+		if !fOpt.isProperty() {
+			// Skip fields that are not properties, such as exclusions (Marked with -), datum, relation and child summary fields
 			continue
 		}
 
@@ -10,8 +10,6 @@ import (
 
 const bufSize = 1024 * 1024
 
-var mockListener *bufconn.Listener
-
 type MockServer struct {
 	proto.UnimplementedKeystoneServer
 	DefineFunc           func(context.Context, *proto.SchemaRequest) (*proto.Schema, error)
@@ -28,18 +26,21 @@ type MockServer struct {
 	SchemaStatisticsFunc func(context.Context, *proto.SchemaStatisticsRequest) (*proto.SchemaStatisticsResponse, error)
 }
 
-func bufDialer(context.Context, string) (net.Conn, error) {
-	return mockListener.Dial()
+// bufDialer dials the listener of a single mock connection, so reconnects never reach another test's server
+func bufDialer(listener *bufconn.Listener) func(context.Context, string) (net.Conn, error) {
+	return func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
 }
 
 // server.Serve(listener) / defer close
 
 func MockConnection() (*Connection, *MockServer, *bufconn.Listener, *grpc.Server) {
-	mockListener = bufconn.Listen(bufSize)
+	mockListener := bufconn.Listen(bufSize)
 	s := grpc.NewServer()
 	m := &MockServer{}
 	proto.RegisterKeystoneServer(s, m)
-	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(bufDialer), grpc.WithInsecure())
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(bufDialer(mockListener)), grpc.WithInsecure())
 	if err != nil {
 		panic(err)
 	}
@@ -89,7 +90,7 @@ func (m *MockServer) GroupCount(ctx context.Context, req *proto.GroupCountReques
 	return m.GroupCountFunc(ctx, req)
 }
 func (m *MockServer) Log(ctx context.Context, req *proto.LogRequest) (*proto.LogResponse, error) {
-	if m.LogsFunc == nil {
+	if m.LogFunc == nil {
 		return m.UnimplementedKeystoneServer.Log(ctx, req)
 	}
 	return m.LogFunc(ctx, req)
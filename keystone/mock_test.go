@@ -0,0 +1,30 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMockServer_Log(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.LogsFunc = func(context.Context, *proto.LogsRequest) (*proto.LogsResponse, error) {
+		return &proto.LogsResponse{}, nil
+	}
+	if _, err := conn.Log(context.Background(), &proto.LogRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Error("Expected Log to be unimplemented when only LogsFunc is set, got", err)
+	}
+
+	server.LogFunc = func(context.Context, *proto.LogRequest) (*proto.LogResponse, error) {
+		return &proto.LogResponse{}, nil
+	}
+	if _, err := conn.Log(context.Background(), &proto.LogRequest{}); err != nil {
+		t.Error("Expected LogFunc to handle Log, got", err)
+	}
+}
@@ -1,15 +1,21 @@
 package keystone
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"time"
 
 	"github.com/kubex/keystone-go/proto"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func (a *Actor) RemoteMutate(ctx context.Context, src interface{}, comment string) error {
+// RemoteMutate writes the measurements, events and logs of an entity by ID, without marshalling its properties
+func (a *Actor) RemoteMutate(ctx context.Context, src interface{}, comment string, options ...MutateOption) error {
 	mutation := &proto.Mutation{}
 	entityID := ""
 	if rawEntity, ok := src.(Entity); ok {
@@ -20,6 +26,13 @@ func (a *Actor) RemoteMutate(ctx context.Context, src interface{}, comment strin
 		return errors.New("entityID is required for remote mutations")
 	}
 
+	settings := getMutateSettings(options)
+	if !settings.dryRun {
+		if err := beforeMutate(ctx, a, src); err != nil {
+			return err
+		}
+	}
+
 	if entityWithSensor, ok := src.(EntitySensorProvider); ok {
 		mutation.Measurements = entityWithSensor.GetKeystoneSensorMeasurements()
 	}
@@ -36,13 +49,63 @@ func (a *Actor) RemoteMutate(ctx context.Context, src interface{}, comment strin
 		Mutation:      mutation,
 	}
 
-	return mutateToError(a.connection.Mutate(ctx, m))
+	for _, option := range options {
+		option.apply(m)
+	}
+
+	if err := a.connection.validateTimestamps(m.GetMutation()); err != nil {
+		return err
+	}
+
+	if settings.dryRun {
+		settings.plan.fill(m, src, nil)
+		return nil
+	}
+
+	mResp, err := a.connection.sendIdempotent(ctx, a.workspaceID, settings, func(ctx context.Context) (*proto.MutateResponse, error) {
+		return a.connection.Mutate(ctx, m)
+	})
+	if err := mutateToError(mResp, err); err != nil {
+		return err
+	}
+
+	a.invalidateParent(src)
+	if !settings.keepStaged {
+		clearStaged(src, mutation)
+	}
+	return afterMutate(ctx, a, src)
 }
 
 type MutateOption interface {
 	apply(*proto.MutateRequest)
 }
 
+// mutateSettings are client side settings for a mutation, which are not sent as part of the request
+type mutateSettings struct {
+	idempotencyKey string
+	retries        int
+	retryBackoff   time.Duration
+	dryRun         bool
+	plan           *MutatePlan
+	keepStaged     bool
+	readBack       []RetrieveOption
+}
+
+// mutateSettingsOption is implemented by a MutateOption that changes how the mutation is sent
+type mutateSettingsOption interface {
+	applySettings(settings *mutateSettings)
+}
+
+func getMutateSettings(options []MutateOption) mutateSettings {
+	settings := mutateSettings{}
+	for _, option := range options {
+		if so, ok := option.(mutateSettingsOption); ok {
+			so.applySettings(&settings)
+		}
+	}
+	return settings
+}
+
 // OnConflictUseID should set the unique properties that can be used to identify an existing identity
 func OnConflictUseID(property ...string) MutateOption {
 	return onConflictUseID{Property: property}
@@ -56,6 +119,29 @@ func (m onConflictUseID) apply(mutate *proto.MutateRequest) {
 	mutate.ConflictUniquePropertyAcquire = m.Property
 }
 
+type keepStaged struct{}
+
+func (m keepStaged) apply(*proto.MutateRequest)             {}
+func (m keepStaged) applySettings(settings *mutateSettings) { settings.keepStaged = true }
+
+// KeepStaged keeps the logs, events, labels and measurements on the entity after a successful mutation
+func KeepStaged() MutateOption {
+	return keepStaged{}
+}
+
+// MutateAt records the mutation as happening at the given time, for backfilling historical data
+func MutateAt(at time.Time) MutateOption {
+	return mutateAt{at: at}
+}
+
+type mutateAt struct {
+	at time.Time
+}
+
+func (m mutateAt) apply(mutate *proto.MutateRequest) {
+	mutate.Mutation.Timestamp = timestamppb.New(m.at)
+}
+
 // MutateProperties Only mutate the specified properties
 func MutateProperties(property ...string) MutateOption {
 	return mutateProperties{Property: property}
@@ -81,15 +167,61 @@ func (m mutateProperties) apply(mutate *proto.MutateRequest) {
 
 // Mutate is a function that can mutate an entity
 func (a *Actor) Mutate(ctx context.Context, src interface{}, comment string, options ...MutateOption) error {
+	_, err := a.mutate(ctx, src, comment, options...)
+	return err
+}
+
+// mutate mutates an entity, returning the keystone response
+func (a *Actor) mutate(ctx context.Context, src interface{}, comment string, options ...MutateOption) (*proto.MutateResponse, error) {
+	m, err := a.buildMutateRequest(ctx, src, comment, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := getMutateSettings(options)
+	if settings.dryRun {
+		settings.plan.fill(m, src, a.connection.plannedSchema(src))
+		return nil, nil
+	}
+
+	mResp, err := a.connection.sendIdempotent(ctx, a.workspaceID, settings, func(ctx context.Context) (*proto.MutateResponse, error) {
+		return a.connection.Mutate(ctx, m)
+	})
+
+	if err == nil && mResp.Success {
+		if rawEntity, ok := src.(Entity); ok && m.GetEntityId() == "" {
+			rawEntity.SetKeystoneID(mResp.GetEntityId())
+		}
+	}
+
+	if err := mutateToError(mResp, err); err != nil {
+		return mResp, err
+	}
+
+	a.invalidateParent(src)
+	refreshLastLoad(src, m.GetMutation())
+	if !settings.keepStaged {
+		clearStaged(src, m.GetMutation())
+	}
+	return mResp, afterMutate(ctx, a, src)
+}
+
+// buildMutateRequest runs the before mutate hook, marshals and validates the entity, and applies the options
+func (a *Actor) buildMutateRequest(ctx context.Context, src interface{}, comment string, options ...MutateOption) (*proto.MutateRequest, error) {
 	if reflect.TypeOf(src).Kind() != reflect.Pointer {
-		return errors.New("mutate requires a pointer to a struct")
+		return nil, errors.New("mutate requires a pointer to a struct")
 	}
 
-	//log.Println("Processing Mutate request")
-	schema, registered := a.connection.registerType(src)
-	if !registered {
-		// wait for the type to be registered with the keystone server
-		a.connection.SyncSchema().Wait()
+	var schema *proto.Schema
+	if getMutateSettings(options).dryRun {
+		// a dry run has no side effects, so hooks are not run and the schema is not defined
+		schema = a.connection.plannedSchema(src)
+	} else {
+		if err := beforeMutate(ctx, a, src); err != nil {
+			return nil, err
+		}
+
+		schema = a.connection.definedSchema(src)
 	}
 	//log.Println("Marshalling entity", src)
 
@@ -98,21 +230,28 @@ func (a *Actor) Mutate(ctx context.Context, src interface{}, comment string, opt
 	mutation.Mutator = a.user
 	entityID := ""
 	mutation.Comment = comment
+
+	datum, err := marshalDatum(src)
+	if err != nil {
+		return nil, err
+	}
+	mutation.Datum = datum
+
+	if err := Validate(src); err != nil {
+		return nil, err
+	}
+
 	if rawEntity, ok := src.(Entity); ok {
 		entityID = rawEntity.GetKeystoneID()
 	}
 
-	if entityWithLabels, ok := src.(EntityLabelProvider); ok {
-		mutation.Labels = entityWithLabels.GetKeystoneLabels()
-	}
+	applyLabelChanges(mutation, src)
 
 	if entityWithSensor, ok := src.(EntitySensorProvider); ok {
 		mutation.Measurements = entityWithSensor.GetKeystoneSensorMeasurements()
 	}
 
-	if entityWithRelationships, ok := src.(EntityRelationshipProvider); ok {
-		mutation.Relationships = entityWithRelationships.GetKeystoneRelationships()
-	}
+	applyRelationshipChanges(mutation, src)
 
 	if entityWithEvents, ok := src.(EntityEventProvider); ok {
 		mutation.Events = entityWithEvents.GetKeystoneEvents()
@@ -122,8 +261,8 @@ func (a *Actor) Mutate(ctx context.Context, src interface{}, comment string, opt
 		mutation.Logs = entityWithLogs.GetKeystoneLogs()
 	}
 
-	if base, ok := src.(BaseEntity); ok && base._lastLoad != nil {
-		mutation.Properties = a.getChangedProperties(base._lastLoad, &proto.EntityResponse{Properties: mutation.Properties})
+	if base, ok := src.(loadTracker); ok && base.keystoneLastLoad() != nil {
+		mutation.Properties = a.getChangedProperties(base.keystoneLastLoad(), &proto.EntityResponse{Properties: mutation.Properties})
 	} else if entityID != "" {
 		mutation.Properties = a.getChangedProperties(nil, &proto.EntityResponse{Properties: mutation.Properties})
 	}
@@ -139,49 +278,128 @@ func (a *Actor) Mutate(ctx context.Context, src interface{}, comment string, opt
 		option.apply(m)
 	}
 
-	mResp, err := a.connection.Mutate(ctx, m)
-
-	if err == nil && mResp.Success {
-		if rawEntity, ok := src.(Entity); ok && entityID == "" {
-			rawEntity.SetKeystoneID(mResp.GetEntityId())
-		}
+	if err := a.connection.validateTimestamps(m.GetMutation()); err != nil {
+		return nil, err
 	}
 
-	return mutateToError(mResp, err)
+	return m, nil
 }
 
 func (a *Actor) getChangedProperties(existing, newValues *proto.EntityResponse) []*proto.EntityProperty {
 	exMap := makeEntityPropertyMap(existing)
-	newMap := makeEntityPropertyMap(newValues)
 
 	var result []*proto.EntityProperty
-	for k, v := range newMap {
-		if _, ok := exMap[k]; !ok {
-			result = append(result, v)
-			continue
-		}
-		if newMap[k].Property == exMap[k].Property &&
-			newMap[k].Value.Text == exMap[k].Value.Text &&
-			newMap[k].Value.SecureText == exMap[k].Value.SecureText &&
-			newMap[k].Value.Int == exMap[k].Value.Int &&
-			newMap[k].Value.Float == exMap[k].Value.Float &&
-			newMap[k].Value.Bool == exMap[k].Value.Bool &&
-			reflect.DeepEqual(newMap[k].Value.Array.KeyValue, exMap[k].Value.Array.KeyValue) &&
-			reflect.DeepEqual(newMap[k].Value.Array.Strings, exMap[k].Value.Array.Strings) &&
-			reflect.DeepEqual(newMap[k].Value.Array.Ints, exMap[k].Value.Array.Ints) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayAppend.KeyValue, exMap[k].Value.ArrayAppend.KeyValue) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayAppend.Strings, exMap[k].Value.ArrayAppend.Strings) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayAppend.Ints, exMap[k].Value.ArrayAppend.Ints) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayReduce.KeyValue, exMap[k].Value.ArrayReduce.KeyValue) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayReduce.Strings, exMap[k].Value.ArrayReduce.Strings) &&
-			reflect.DeepEqual(newMap[k].Value.ArrayReduce.Ints, exMap[k].Value.ArrayReduce.Ints) &&
-			reflect.DeepEqual(newMap[k].Value.Time, exMap[k].Value.Time) {
+	for _, v := range newValues.GetProperties() {
+		if ex, ok := exMap[v.Property]; ok && valuesEqual(ex.GetValue(), v.GetValue()) {
 			continue
 		}
+		result = append(result, v)
 	}
 	return result
 }
 
+// refreshLastLoad merges the mutated properties and datum into the last load of src
+// Later mutations then only send properties changed since this mutation, and immutable checks use the stored values
+func refreshLastLoad(src interface{}, mutation *proto.Mutation) {
+	tracker, ok := src.(loadTracker)
+	if !ok || tracker.keystoneLastLoad() == nil {
+		return
+	}
+
+	loaded := protobuf.Clone(tracker.keystoneLastLoad()).(*proto.EntityResponse)
+	index := make(map[string]int, len(loaded.GetProperties()))
+	for i, p := range loaded.GetProperties() {
+		index[p.GetProperty()] = i
+	}
+	for _, p := range mutation.GetProperties() {
+		if i, ok := index[p.GetProperty()]; ok {
+			loaded.Properties[i] = p
+		} else {
+			loaded.Properties = append(loaded.Properties, p)
+		}
+	}
+	switch {
+	case bytes.Equal(mutation.GetDatum(), clearedDatum):
+		loaded.Datum = nil
+	case mutation.GetDatum() != nil:
+		loaded.Datum = &proto.EntityDatum{Data: mutation.GetDatum()}
+	}
+	tracker.setKeystoneLastLoad(loaded)
+}
+
+// valuesEqual returns true if both values hold the same data
+func valuesEqual(a, b *proto.Value) bool {
+	return a.GetText() == b.GetText() &&
+		a.GetSecureText() == b.GetSecureText() &&
+		a.GetInt() == b.GetInt() &&
+		a.GetFloat() == b.GetFloat() &&
+		a.GetBool() == b.GetBool() &&
+		bytes.Equal(a.GetRaw(), b.GetRaw()) &&
+		a.GetTime().GetSeconds() == b.GetTime().GetSeconds() &&
+		a.GetTime().GetNanos() == b.GetTime().GetNanos() &&
+		repeatedValuesEqual(a.GetArray(), b.GetArray()) &&
+		repeatedValuesEqual(a.GetArrayAppend(), b.GetArrayAppend()) &&
+		repeatedValuesEqual(a.GetArrayReduce(), b.GetArrayReduce())
+}
+
+func repeatedValuesEqual(a, b *proto.RepeatedValue) bool {
+	if len(a.GetKeyValue()) != len(b.GetKeyValue()) {
+		return false
+	}
+	for k, v := range a.GetKeyValue() {
+		if bv, ok := b.GetKeyValue()[k]; !ok || !bytes.Equal(v, bv) {
+			return false
+		}
+	}
+	return slices.Equal(a.GetStrings(), b.GetStrings()) && slices.Equal(a.GetInts(), b.GetInts())
+}
+
+// clearStaged clears the logs, events, labels, relationships and measurements sent with a successful mutation
+// Entities tracking label and relationship changes mark the sent changes as loaded
+func clearStaged(src interface{}, mutation *proto.Mutation) {
+	if entityWithLogs, ok := src.(EntityLogProvider); ok && len(mutation.GetLogs()) > 0 {
+		_ = entityWithLogs.ClearKeystoneLogs()
+	}
+	if entityWithEvents, ok := src.(EntityEventProvider); ok && len(mutation.GetEvents()) > 0 {
+		_ = entityWithEvents.ClearKeystoneEvents()
+	}
+	if len(mutation.GetLabels())+len(mutation.GetRemoveLabels()) > 0 {
+		if committer, ok := src.(labelCommitter); ok {
+			committer.commitKeystoneLabels()
+		} else if entityWithLabels, ok := src.(EntityLabelProvider); ok {
+			_ = entityWithLabels.ClearKeystoneLabels()
+		}
+	}
+	if len(mutation.GetRelationships())+len(mutation.GetRemoveRelationships()) > 0 {
+		if committer, ok := src.(relationshipCommitter); ok {
+			committer.commitKeystoneRelationships()
+		} else if entityWithRelationships, ok := src.(EntityRelationshipProvider); ok {
+			_ = entityWithRelationships.ClearKeystoneRelationships()
+		}
+	}
+	if entityWithSensor, ok := src.(EntitySensorProvider); ok && len(mutation.GetMeasurements()) > 0 {
+		_ = entityWithSensor.ClearKeystoneSensorMeasurements()
+	}
+}
+
+// applyLabelChanges sets the labels to add and remove on the mutation
+func applyLabelChanges(mutation *proto.Mutation, src interface{}) {
+	if entityWithLabels, ok := src.(EntityLabelSyncer); ok {
+		mutation.Labels, mutation.RemoveLabels = entityWithLabels.GetKeystoneLabelChanges()
+	} else if entityWithLabels, ok := src.(EntityLabelProvider); ok {
+		mutation.Labels = entityWithLabels.GetKeystoneLabels()
+	}
+}
+
+// applyRelationshipChanges sets the relationships to add and remove on the mutation
+func applyRelationshipChanges(mutation *proto.Mutation, src interface{}) {
+	if entityWithRelationships, ok := src.(EntityRelationshipSyncer); ok {
+		mutation.Relationships, mutation.RemoveRelationships = entityWithRelationships.GetKeystoneRelationshipChanges()
+	} else if entityWithRelationships, ok := src.(EntityRelationshipProvider); ok {
+		mutation.Relationships = entityWithRelationships.GetKeystoneRelationships()
+	}
+}
+
 func mutateToError(resp *proto.MutateResponse, err error) error {
 	if err != nil {
 		return err
@@ -0,0 +1,156 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestActor_RemoteMutateClearsStaged(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+	actor := conn.Actor("workspace", "", "", "")
+
+	kept := RemoteEntity("abc")
+	kept.LogInfo("kept", "", "", "", nil)
+	if err := actor.RemoteMutate(context.Background(), kept, "", KeepStaged()); err != nil {
+		t.Fatal(err)
+	}
+	if len(kept.GetKeystoneLogs()) != 1 {
+		t.Error("Expected logs to be kept")
+	}
+
+	cleared := RemoteEntity("abc")
+	cleared.LogInfo("cleared", "", "", "", nil)
+	cleared.AddKeystoneEvent("event", nil)
+	if err := actor.RemoteMutate(context.Background(), cleared, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(cleared.GetKeystoneLogs()) != 0 || len(cleared.GetKeystoneEvents()) != 0 {
+		t.Error("Expected logs and events to be cleared")
+	}
+}
+
+type testTrackedEntity struct {
+	BaseEntity
+	Name string
+	City string
+}
+
+func TestActor_MutateSendsChangedProperties(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		return &proto.EntityResponse{
+			Entity: &proto.Entity{EntityId: req.GetEntityId()},
+			Properties: []*proto.EntityProperty{
+				{Property: "name", Value: &proto.Value{Text: "Ant"}},
+				{Property: "city", Value: &proto.Value{Text: "Leeds"}},
+			},
+		}, nil
+	}
+	var sent []string
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		sent = nil
+		for _, p := range req.GetMutation().GetProperties() {
+			sent = append(sent, p.GetProperty()+"="+p.GetValue().GetText())
+		}
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	ctx := context.Background()
+	actor := conn.Actor("workspace", "", "", "")
+	entity := &testTrackedEntity{}
+	if err := actor.GetByID(ctx, "a", entity); err != nil {
+		t.Fatal(err)
+	}
+
+	entity.Name = "Bee"
+	if err := actor.Mutate(ctx, entity, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0] != "name=Bee" {
+		t.Error("Expected only the changed name to be sent, got", sent)
+	}
+
+	if err := actor.Mutate(ctx, entity, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Error("Expected no properties once the change was sent, got", sent)
+	}
+
+	entity.City = "York"
+	if err := actor.Mutate(ctx, entity, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0] != "city=York" {
+		t.Error("Expected only the changed city to be sent, got", sent)
+	}
+
+	unloaded := &testTrackedEntity{Name: "Ant", City: "Leeds"}
+	unloaded.SetKeystoneID("a")
+	if err := actor.Mutate(ctx, unloaded, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 {
+		t.Error("Expected all properties for an entity that was not loaded, got", sent)
+	}
+}
+
+func TestActor_MutateCommitsLabelsAndRelationships(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	var sent *proto.Mutation
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		sent = req.GetMutation()
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	ctx := context.Background()
+	actor := conn.Actor("workspace", "", "", "")
+	entity := &testTrackedEntity{Name: "Ant"}
+	entity.SetKeystoneID("a")
+	entity.HydrateKeystoneLabels([]*proto.EntityLabel{{Name: "tier", Value: "gold"}})
+	entity.HydrateKeystoneRelationships([]*proto.EntityRelationship{loadedRelationship("owner", "u1")})
+
+	entity.AddKeystoneLabel("region", "eu")
+	entity.AddKeystoneRelationship("member", "g1", nil, time.Now())
+	entity.RemoveKeystoneRelationship("owner", "u1")
+	if err := actor.Mutate(ctx, entity, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.GetLabels()) != 1 || len(sent.GetRelationships()) != 1 || len(sent.GetRemoveRelationships()) != 1 {
+		t.Fatal("Expected the staged label and relationship changes to be sent, got", sent)
+	}
+
+	if err := actor.Mutate(ctx, entity, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.GetLabels())+len(sent.GetRemoveLabels())+len(sent.GetRelationships())+len(sent.GetRemoveRelationships()) != 0 {
+		t.Error("Expected sent changes to be loaded rather than resent, got", sent)
+	}
+	if len(entity.GetKeystoneLabels()) != 2 {
+		t.Error("Expected the sent label to be loaded, got", entity.GetKeystoneLabels())
+	}
+	if relationships := entity.GetKeystoneRelationships(); len(relationships) != 1 || relationships[0].GetTargetId() != "g1" {
+		t.Error("Expected only the added relationship to remain, got", relationships)
+	}
+}
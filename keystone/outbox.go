@@ -0,0 +1,354 @@
+package keystone
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// ErrMutationQueued is returned when keystone is unreachable and the request has been written to the outbox
+var ErrMutationQueued = errors.New("keystone unavailable, request queued in outbox")
+
+// ErrOutboxClosed is returned when a request would be queued after the outbox has been closed
+var ErrOutboxClosed = errors.New("keystone unavailable, outbox closed")
+
+type outboxKind byte
+
+const (
+	outboxMutate outboxKind = iota + 1
+	outboxLog
+	outboxTimeSeries
+)
+
+type outboxRecord struct {
+	kind           outboxKind
+	idempotencyKey string
+	message        protobuf.Message
+}
+
+// OutboxRejection is a queued request keystone rejected when it was replayed
+type OutboxRejection struct {
+	Request protobuf.Message
+	Err     error
+}
+
+// Outbox persists requests that could not be sent to keystone in an append only file, replaying them in order
+type Outbox struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	pending    []outboxRecord
+	rejected   []OutboxRejection
+	closed     bool
+	connection *Connection
+	draining   sync.Mutex
+	stop       chan struct{}
+}
+
+// EnableOutbox queues requests in the file at path while keystone is unreachable, replaying every replayInterval
+func (c *Connection) EnableOutbox(path string, replayInterval time.Duration) (*Outbox, error) {
+	o := &Outbox{path: path, connection: c, stop: make(chan struct{})}
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	o.file = file
+	c.outbox = o
+
+	if replayInterval > 0 {
+		go o.replayLoop(replayInterval)
+	}
+	return o, nil
+}
+
+// Outbox returns the outbox, or nil when it has not been enabled
+func (c *Connection) Outbox() *Outbox { return c.outbox }
+
+// Depth returns the number of requests waiting to be replayed
+func (o *Outbox) Depth() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Rejected returns the queued requests keystone rejected when replayed, clearing them from the outbox
+func (o *Outbox) Rejected() []OutboxRejection {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	rejected := o.rejected
+	o.rejected = nil
+	return rejected
+}
+
+// Close stops replaying and closes the outbox file, queued requests remain on disk
+// Requests that would be queued after Close fail with ErrOutboxClosed
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	close(o.stop)
+	return o.file.Close()
+}
+
+// Drain replays queued requests in order through the connection, stopping at the first request keystone cannot be reached for
+// It returns the number of requests keystone accepted, requests it rejected are removed and available from Rejected
+func (o *Outbox) Drain(ctx context.Context) (int, error) {
+	o.draining.Lock()
+	defer o.draining.Unlock()
+
+	sent, removed := 0, 0
+	var sendErr error
+	for {
+		o.mu.Lock()
+		if len(o.pending) == 0 {
+			o.mu.Unlock()
+			break
+		}
+		record := o.pending[0]
+		o.mu.Unlock()
+
+		err := o.send(ctx, record)
+		if err != nil && (isUnavailable(err) || ctx.Err() != nil) {
+			sendErr = err
+			break
+		}
+
+		o.mu.Lock()
+		o.pending = o.pending[1:]
+		if err != nil {
+			// keystone rejected the request, replaying it again will not succeed
+			o.rejected = append(o.rejected, OutboxRejection{Request: record.message, Err: err})
+		} else {
+			sent++
+		}
+		o.mu.Unlock()
+		removed++
+	}
+
+	if removed > 0 {
+		if err := o.compact(); err != nil {
+			return sent, err
+		}
+	}
+	return sent, sendErr
+}
+
+func (o *Outbox) replayLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			if o.Depth() > 0 {
+				_, _ = o.Drain(context.Background())
+			}
+		}
+	}
+}
+
+// send replays the record through the connection with the idempotency key it was first sent with
+func (o *Outbox) send(ctx context.Context, record outboxRecord) error {
+	if record.idempotencyKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, IdempotencyKeyHeader, record.idempotencyKey)
+	}
+
+	switch msg := record.message.(type) {
+	case *proto.MutateRequest:
+		return mutateToError(o.connection.mutate(ctx, msg))
+	case *proto.ReportTimeSeriesRequest:
+		return mutateToError(o.connection.reportTimeSeries(ctx, msg))
+	case *proto.LogRequest:
+		_, err := o.connection.log(ctx, msg)
+		return err
+	}
+	return fmt.Errorf("unsupported outbox request %T", record.message)
+}
+
+// outboxIdempotencyKey returns the idempotency key the request is sent with, attaching a new key when there is none
+// A request that fails as unavailable may still have reached keystone, replaying it with the same key keeps it from applying twice
+func outboxIdempotencyKey(ctx context.Context) (context.Context, string) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if keys := md.Get(IdempotencyKeyHeader); len(keys) > 0 {
+			return ctx, keys[len(keys)-1]
+		}
+	}
+	key := newIdempotencyKey()
+	return metadata.AppendToOutgoingContext(ctx, IdempotencyKeyHeader, key), key
+}
+
+// queueing returns true when requests must be queued to preserve ordering with those already queued
+func (o *Outbox) queueing() bool {
+	return o != nil && o.Depth() > 0
+}
+
+// queue writes the request to the outbox, returning ErrMutationQueued once it is persisted
+func (o *Outbox) queue(kind outboxKind, idempotencyKey string, message protobuf.Message) error {
+	if err := o.enqueue(outboxRecord{kind: kind, idempotencyKey: idempotencyKey, message: message}); err != nil {
+		return err
+	}
+	return ErrMutationQueued
+}
+
+// enqueue appends the record to the outbox file, requests keep the timestamps they were created with
+func (o *Outbox) enqueue(record outboxRecord) error {
+	frame, err := frameRecord(record)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrOutboxClosed
+	}
+	if _, err := o.file.Write(frame); err != nil {
+		return err
+	}
+	if err := o.file.Sync(); err != nil {
+		return err
+	}
+	o.pending = append(o.pending, record)
+	return nil
+}
+
+// frameRecord encodes a record as its kind, the uvarint length prefixed idempotency key, then the uvarint length prefixed protobuf message
+func frameRecord(record outboxRecord) ([]byte, error) {
+	data, err := protobuf.Marshal(record.message)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, 1, 1+2*binary.MaxVarintLen64+len(record.idempotencyKey)+len(data))
+	frame[0] = byte(record.kind)
+	frame = binary.AppendUvarint(frame, uint64(len(record.idempotencyKey)))
+	frame = append(frame, record.idempotencyKey...)
+	frame = binary.AppendUvarint(frame, uint64(len(data)))
+	return append(frame, data...), nil
+}
+
+// readFrameField reads a uvarint length prefixed field, returning false when the data ends before the field does
+func readFrameField(data []byte) ([]byte, int, bool) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < size {
+		return nil, 0, false
+	}
+	return data[n : n+int(size)], n + int(size), true
+}
+
+// load reads queued requests from the outbox file, truncating a partially written final record
+func (o *Outbox) load() error {
+	data, err := os.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	offset := 0
+	for offset < len(data) {
+		kind := outboxKind(data[offset])
+		key, keyLen, ok := readFrameField(data[offset+1:])
+		if !ok {
+			break
+		}
+		body, bodyLen, ok := readFrameField(data[offset+1+keyLen:])
+		if !ok {
+			break
+		}
+
+		var message protobuf.Message
+		switch kind {
+		case outboxMutate:
+			message = &proto.MutateRequest{}
+		case outboxLog:
+			message = &proto.LogRequest{}
+		case outboxTimeSeries:
+			message = &proto.ReportTimeSeriesRequest{}
+		default:
+			return fmt.Errorf("unknown outbox record kind %d", kind)
+		}
+		if err := protobuf.Unmarshal(body, message); err != nil {
+			return err
+		}
+		o.pending = append(o.pending, outboxRecord{kind: kind, idempotencyKey: string(key), message: message})
+		offset += 1 + keyLen + bodyLen
+	}
+
+	if offset < len(data) {
+		// the final record was not completely written, drop it so new records are not appended after it
+		return os.Truncate(o.path, int64(offset))
+	}
+	return nil
+}
+
+// compact rewrites the outbox file with only the pending requests
+func (o *Outbox) compact() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	tmpPath := o.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, record := range o.pending {
+		frame, err := frameRecord(record)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		_, _ = writer.Write(frame)
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if o.closed {
+		return os.Rename(tmpPath, o.path)
+	}
+	if err := o.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, o.path); err != nil {
+		return err
+	}
+	o.file, err = os.OpenFile(o.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+func isUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
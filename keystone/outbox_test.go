@@ -0,0 +1,212 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestOutbox_Persisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox")
+	c := &Connection{}
+
+	o, err := c.EnableOutbox(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := timestamppb.New(time.Now().Add(-time.Hour))
+	req := &proto.MutateRequest{EntityId: "abc", Mutation: &proto.Mutation{Comment: "queued", Timestamp: at}}
+	if err := o.queue(outboxMutate, "key-1", req); !errors.Is(err, ErrMutationQueued) {
+		t.Fatal("Expected ErrMutationQueued, got", err)
+	}
+	if err := o.queue(outboxLog, "", &proto.LogRequest{EntityId: "abc"}); !errors.Is(err, ErrMutationQueued) {
+		t.Fatal("Expected ErrMutationQueued, got", err)
+	}
+	_ = o.Close()
+
+	if err := o.queue(outboxLog, "", &proto.LogRequest{EntityId: "abc"}); !errors.Is(err, ErrOutboxClosed) {
+		t.Error("Expected ErrOutboxClosed after close, got", err)
+	}
+
+	reopened, err := c.EnableOutbox(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Depth() != 2 {
+		t.Fatal("Expected 2 queued requests, got", reopened.Depth())
+	}
+
+	replayed, ok := reopened.pending[0].message.(*proto.MutateRequest)
+	if !ok || replayed.GetMutation().GetComment() != "queued" {
+		t.Fatal("Expected the mutate request first, got", reopened.pending[0].message)
+	}
+	if reopened.pending[0].idempotencyKey != "key-1" {
+		t.Error("Expected the idempotency key to be persisted, got", reopened.pending[0].idempotencyKey)
+	}
+	if !replayed.GetMutation().GetTimestamp().AsTime().Equal(at.AsTime()) {
+		t.Error("Expected the original mutation timestamp to be preserved")
+	}
+}
+
+func TestOutbox_TornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox")
+	c := &Connection{}
+
+	o, err := c.EnableOutbox(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = o.queue(outboxLog, "a", &proto.LogRequest{EntityId: "a"})
+	_ = o.queue(outboxLog, "b", &proto.LogRequest{EntityId: "b"})
+	_ = o.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	complete := info.Size()
+
+	// simulate a crash part way through writing a third record
+	frame, _ := frameRecord(outboxRecord{kind: outboxLog, idempotencyKey: "c", message: &proto.LogRequest{EntityId: "c"}})
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = file.Write(frame[:len(frame)-2])
+	_ = file.Close()
+
+	o, err = c.EnableOutbox(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Depth() != 2 {
+		t.Fatal("Expected the torn record to be ignored, got", o.Depth())
+	}
+	if info, _ := os.Stat(path); info.Size() != complete {
+		t.Fatal("Expected the torn record to be truncated, file is", info.Size(), "bytes, expected", complete)
+	}
+
+	_ = o.queue(outboxLog, "d", &proto.LogRequest{EntityId: "d"})
+	_ = o.Close()
+
+	o, err = c.EnableOutbox(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	var ids []string
+	for _, record := range o.pending {
+		ids = append(ids, record.message.(*proto.LogRequest).GetEntityId())
+	}
+	if !slices.Equal(ids, []string{"a", "b", "d"}) {
+		t.Error("Expected records queued after the torn record to be readable, got", ids)
+	}
+}
+
+func TestOutbox_Drain(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	var mu sync.Mutex
+	available := false
+	var received, keys []string
+	record := func(ctx context.Context, entityID string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys = append(keys, md.Get(IdempotencyKeyHeader)...)
+		if !available {
+			return status.Error(codes.Unavailable, "down")
+		}
+		received = append(received, entityID)
+		return nil
+	}
+	server.MutateFunc = func(ctx context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		if err := record(ctx, req.GetEntityId()); err != nil {
+			return nil, err
+		}
+		if req.GetEntityId() == "rejected" {
+			return &proto.MutateResponse{ErrorCode: 400, ErrorMessage: "invalid"}, nil
+		}
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+	server.LogFunc = func(ctx context.Context, req *proto.LogRequest) (*proto.LogResponse, error) {
+		return &proto.LogResponse{}, record(ctx, "log-"+req.GetEntityId())
+	}
+
+	o, err := conn.EnableOutbox(filepath.Join(t.TempDir(), "outbox"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	cache := conn.EnableCache(CacheConfig{})
+	cache.set("a-view", cacheEntityKey("workspace", "a"), cache.currentGeneration(), &proto.EntityResponse{}, nil)
+
+	ctx := context.Background()
+	if _, err := conn.Mutate(ctx, &proto.MutateRequest{EntityId: "a", Authorization: &proto.Authorization{WorkspaceId: "workspace"}}); !errors.Is(err, ErrMutationQueued) {
+		t.Fatal("Expected ErrMutationQueued, got", err)
+	}
+	cache.set("a-view", cacheEntityKey("workspace", "a"), cache.currentGeneration(), &proto.EntityResponse{}, nil)
+	if _, err := conn.Log(ctx, &proto.LogRequest{EntityId: "a"}); !errors.Is(err, ErrMutationQueued) {
+		t.Fatal("Expected requests after a queued request to be queued, got", err)
+	}
+	if _, err := conn.Mutate(ctx, &proto.MutateRequest{EntityId: "rejected", Mutation: &proto.Mutation{}}); !errors.Is(err, ErrMutationQueued) {
+		t.Fatal("Expected ErrMutationQueued, got", err)
+	}
+	if o.pending[2].message.(*proto.MutateRequest).GetMutation().GetTimestamp() != nil {
+		t.Error("Expected queued requests to keep the caller's timestamp rather than the queue time")
+	}
+	if o.Depth() != 3 {
+		t.Fatal("Expected 3 queued requests, got", o.Depth())
+	}
+
+	mu.Lock()
+	available = true
+	firstKey := keys[0]
+	keys = nil
+	mu.Unlock()
+
+	sent, err := o.Drain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Error("Expected 2 accepted requests, got", sent)
+	}
+	if !slices.Equal(received, []string{"a", "log-a", "rejected"}) {
+		t.Error("Expected requests to be replayed in order, got", received)
+	}
+	if len(keys) != 3 || keys[0] != firstKey {
+		t.Error("Expected the replay to resend the original idempotency key", firstKey, "got", keys)
+	}
+	if cache.Len() != 0 {
+		t.Error("Expected the replayed mutation to invalidate the cache")
+	}
+
+	rejected := o.Rejected()
+	if len(rejected) != 1 || rejected[0].Request.(*proto.MutateRequest).GetEntityId() != "rejected" || rejected[0].Err == nil {
+		t.Error("Expected the rejected request to be collected, got", rejected)
+	}
+	if o.Depth() != 0 || len(o.Rejected()) != 0 {
+		t.Error("Expected the outbox to be empty after draining")
+	}
+
+	_ = o.Close()
+	mu.Lock()
+	available = false
+	mu.Unlock()
+	if _, err := conn.Mutate(ctx, &proto.MutateRequest{EntityId: "a"}); !errors.Is(err, ErrOutboxClosed) {
+		t.Error("Expected ErrOutboxClosed when queueing after close, got", err)
+	}
+}
@@ -0,0 +1,114 @@
+package keystone
+
+import (
+	"context"
+	"slices"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// DefaultPageSize is the number of entities requested per page when a pager is created without a page size
+const DefaultPageSize int32 = 100
+
+// Pager walks every page of a list, using the last entity ID of each page as the cursor for the next
+type Pager struct {
+	fetch   func(ctx context.Context, afterID string) ([]*proto.EntityResponse, string, int32, error)
+	afterID string
+	total   int32
+	done    bool
+}
+
+// PageResult is a single entity, or the error that stopped the pager
+type PageResult struct {
+	Entity *proto.EntityResponse
+	Err    error
+}
+
+// ListPager returns a pager over all entities within an active set
+func (a *Actor) ListPager(entityType string, retrieveProperties []string, perPage int32, options ...FindOption) *Pager {
+	if perPage <= 0 {
+		perPage = DefaultPageSize
+	}
+	return &Pager{fetch: func(ctx context.Context, afterID string) ([]*proto.EntityResponse, string, int32, error) {
+		resp, err := a.list(ctx, entityType, retrieveProperties, append(slices.Clone(options), After(perPage, afterID))...)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		lastID := resp.GetLastId()
+		if int32(len(resp.GetEntities())) < perPage {
+			lastID = ""
+		}
+		return resp.GetEntities(), lastID, resp.GetTotalResults(), nil
+	}}
+}
+
+// FindPager returns a pager over the entities matching the find options
+// Find requests do not support cursors, so all results are returned as a single page
+func (a *Actor) FindPager(entityType string, retrieve RetrieveOption, options ...FindOption) *Pager {
+	return &Pager{fetch: func(ctx context.Context, _ string) ([]*proto.EntityResponse, string, int32, error) {
+		resp, err := a.find(ctx, entityType, retrieve, options...)
+		return resp.GetEntities(), "", resp.GetTotalResults(), err
+	}}
+}
+
+// TotalResults returns the total number of results reported by the most recent page
+func (p *Pager) TotalResults() int32 { return p.total }
+
+// Done returns true once the final page has been returned
+func (p *Pager) Done() bool { return p.done }
+
+// Next returns the next page of entities, returning nil once all pages have been read
+func (p *Pager) Next(ctx context.Context) ([]*proto.EntityResponse, error) {
+	if p.done {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entities, lastID, total, err := p.fetch(ctx, p.afterID)
+	if err != nil {
+		return nil, err
+	}
+	p.total = total
+	if lastID == "" || lastID == p.afterID || len(entities) == 0 {
+		p.done = true
+	}
+	p.afterID = lastID
+	return entities, nil
+}
+
+// All iterates every remaining entity, and is compatible with iter.Seq2 for range over func
+func (p *Pager) All(ctx context.Context) func(yield func(*proto.EntityResponse, error) bool) {
+	return func(yield func(*proto.EntityResponse, error) bool) {
+		for !p.done {
+			entities, err := p.Next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, entity := range entities {
+				if !yield(entity, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Chan streams every remaining entity, the channel is closed once all pages are read or ctx is cancelled
+func (p *Pager) Chan(ctx context.Context) <-chan PageResult {
+	results := make(chan PageResult)
+	go func() {
+		defer close(results)
+		p.All(ctx)(func(entity *proto.EntityResponse, err error) bool {
+			select {
+			case results <- PageResult{Entity: entity, Err: err}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return results
+}
@@ -0,0 +1,69 @@
+package keystone
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestListPager(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.ListFunc = func(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+		start := 0
+		if req.GetPage().GetAfterId() != "" {
+			start, _ = strconv.Atoi(req.GetPage().GetAfterId())
+		}
+		resp := &proto.ListResponse{TotalResults: 5}
+		for i := start + 1; i <= 5 && len(resp.Entities) < int(req.GetPage().GetPerPage()); i++ {
+			resp.Entities = append(resp.Entities, &proto.EntityResponse{Entity: &proto.Entity{EntityId: strconv.Itoa(i)}})
+			resp.LastId = strconv.Itoa(i)
+		}
+		return resp, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	pager := actor.ListPager("test", nil, 2)
+
+	var ids []string
+	pager.All(context.Background())(func(entity *proto.EntityResponse, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, entity.GetEntity().GetEntityId())
+		return true
+	})
+
+	if len(ids) != 5 || ids[0] != "1" || ids[4] != "5" {
+		t.Error("Expected entities 1 to 5, got", ids)
+	}
+	if pager.TotalResults() != 5 {
+		t.Error("Expected 5 total results, got", pager.TotalResults())
+	}
+
+	var streamed int
+	for result := range actor.ListPager("test", nil, 2).Chan(context.Background()) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		streamed++
+	}
+	if streamed != 5 {
+		t.Error("Expected 5 streamed entities, got", streamed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var cancelled error
+	actor.ListPager("test", nil, 2).All(ctx)(func(_ *proto.EntityResponse, err error) bool {
+		cancelled = err
+		return true
+	})
+	if cancelled == nil {
+		t.Error("Expected cancelled context to stop the pager")
+	}
+}
@@ -0,0 +1,238 @@
+package keystone
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// MutatePlan is the request a mutation would send, filled by DryRun
+type MutatePlan struct {
+	Request  *proto.MutateRequest
+	Previous *proto.EntityResponse // the entity as last loaded, when known
+	Schema   *proto.Schema         // used to render property values by type, when known
+}
+
+// String renders the plan, showing property changes against the previous entity
+func (p *MutatePlan) String() string {
+	return renderMutatePlan(p.Request, p.Previous, p.Schema)
+}
+
+type dryRun struct{ plan *MutatePlan }
+
+func (m dryRun) apply(*proto.MutateRequest) {}
+func (m dryRun) applySettings(settings *mutateSettings) {
+	settings.dryRun = true
+	settings.plan = m.plan
+}
+
+// DryRun builds the mutation without sending it to keystone, filling plan when it is not nil
+// Before mutate hooks are not run, and the schema is not defined with keystone
+func DryRun(plan *MutatePlan) MutateOption {
+	return dryRun{plan: plan}
+}
+
+// PlanMutate returns the request Mutate would send for the entity, without running hooks or sending any request
+// Render the request with RenderMutatePlan, or use DryRun for a plan that also renders against the last load and schema
+func (a *Actor) PlanMutate(ctx context.Context, src interface{}, comment string, options ...MutateOption) (*proto.MutateRequest, error) {
+	plan := &MutatePlan{}
+	if err := a.Mutate(ctx, src, comment, append(slices.Clone(options), DryRun(plan))...); err != nil {
+		return nil, err
+	}
+	return plan.Request, nil
+}
+
+// fill records the request, with the last load of src and the schema, when the plan was requested
+func (p *MutatePlan) fill(req *proto.MutateRequest, src interface{}, schema *proto.Schema) {
+	if p == nil {
+		return
+	}
+	p.Request = req
+	p.Schema = schema
+	if base, ok := src.(loadTracker); ok {
+		p.Previous = base.keystoneLastLoad()
+	}
+}
+
+// RenderMutatePlan describes a mutate request, showing property changes against the previous entity when provided
+func RenderMutatePlan(req *proto.MutateRequest, previous *proto.EntityResponse) string {
+	return renderMutatePlan(req, previous, nil)
+}
+
+func renderMutatePlan(req *proto.MutateRequest, previous *proto.EntityResponse, schema *proto.Schema) string {
+	dataTypes := make(map[string]proto.Property_Type, len(schema.GetProperties()))
+	for _, p := range schema.GetProperties() {
+		dataTypes[p.GetName()] = p.GetDataType()
+	}
+
+	sb := &strings.Builder{}
+	mutation := req.GetMutation()
+
+	entityID := req.GetEntityId()
+	if entityID == "" {
+		entityID = "(new)"
+	}
+	fmt.Fprintf(sb, "mutate %s %s", req.GetSchema().GetKey(), entityID)
+	if mutation.GetComment() != "" {
+		fmt.Fprintf(sb, " %q", mutation.GetComment())
+	}
+	sb.WriteString("\n")
+
+	existing := makeEntityPropertyMap(previous)
+	if len(mutation.GetProperties()) > 0 {
+		sb.WriteString("properties:\n")
+		for _, p := range mutation.GetProperties() {
+			dataType, typed := dataTypes[p.GetProperty()]
+			if ex, ok := existing[p.GetProperty()]; ok {
+				fmt.Fprintf(sb, "  ~ %s: %s => %s\n", p.GetProperty(), formatValue(ex.GetValue(), dataType, typed), formatValue(p.GetValue(), dataType, typed))
+			} else {
+				fmt.Fprintf(sb, "  + %s: %s\n", p.GetProperty(), formatValue(p.GetValue(), dataType, typed))
+			}
+		}
+	}
+
+	if len(mutation.GetLabels()) > 0 || len(mutation.GetRemoveLabels()) > 0 {
+		sb.WriteString("labels:\n")
+		for _, l := range mutation.GetLabels() {
+			fmt.Fprintf(sb, "  + %s=%s\n", l.GetName(), l.GetValue())
+		}
+		for _, l := range mutation.GetRemoveLabels() {
+			fmt.Fprintf(sb, "  - %s=%s\n", l.GetName(), l.GetValue())
+		}
+	}
+
+	if len(mutation.GetRelationships()) > 0 || len(mutation.GetRemoveRelationships()) > 0 {
+		sb.WriteString("relationships:\n")
+		for _, r := range mutation.GetRelationships() {
+			fmt.Fprintf(sb, "  + %s -> %s\n", r.GetRelationship().GetKey(), r.GetTargetId())
+		}
+		for _, r := range mutation.GetRemoveRelationships() {
+			fmt.Fprintf(sb, "  - %s -> %s\n", r.GetRelationship().GetKey(), r.GetTargetId())
+		}
+	}
+
+	if len(mutation.GetChildren()) > 0 || len(mutation.GetRemoveChildren()) > 0 {
+		sb.WriteString("children:\n")
+		for _, c := range mutation.GetChildren() {
+			fmt.Fprintf(sb, "  + %s %s value=%d %s\n", c.GetType().GetKey(), c.GetCid(), c.GetValue(), formatBytesMap(c.GetData()))
+		}
+		for _, c := range mutation.GetRemoveChildren() {
+			fmt.Fprintf(sb, "  - %s %s\n", c.GetType().GetKey(), c.GetCid())
+		}
+	}
+
+	if len(mutation.GetLogs()) > 0 {
+		sb.WriteString("logs:\n")
+		for _, l := range mutation.GetLogs() {
+			fmt.Fprintf(sb, "  [%s] %s %s\n", l.GetLevel(), l.GetMessage(), formatStringMap(l.GetData()))
+		}
+	}
+
+	if len(mutation.GetEvents()) > 0 {
+		sb.WriteString("events:\n")
+		for _, e := range mutation.GetEvents() {
+			fmt.Fprintf(sb, "  %s %s\n", e.GetType().GetKey(), formatStringMap(e.GetData()))
+		}
+	}
+
+	if len(mutation.GetMeasurements()) > 0 {
+		sb.WriteString("measurements:\n")
+		for _, m := range mutation.GetMeasurements() {
+			fmt.Fprintf(sb, "  %s = %v\n", m.GetSensor(), m.GetValue())
+		}
+	}
+
+	if len(mutation.GetDatum()) > 0 {
+		fmt.Fprintf(sb, "datum: %d bytes\n", len(mutation.GetDatum()))
+	}
+
+	return sb.String()
+}
+
+// formatValue renders the value, zero numbers and false are only shown when the property type is known
+func formatValue(v *proto.Value, dataType proto.Property_Type, typed bool) string {
+	var parts []string
+	if v.GetText() != "" {
+		parts = append(parts, fmt.Sprintf("%q", v.GetText()))
+	}
+	if v.GetSecureText() != "" {
+		parts = append(parts, "<secure>")
+	}
+	if v.GetInt() != 0 {
+		parts = append(parts, fmt.Sprintf("%d", v.GetInt()))
+	}
+	if v.GetFloat() != 0 {
+		parts = append(parts, fmt.Sprintf("%v", v.GetFloat()))
+	}
+	if v.GetBool() {
+		parts = append(parts, "true")
+	}
+	if v.GetTime() != nil {
+		parts = append(parts, v.GetTime().AsTime().Format(time.RFC3339))
+	}
+	if len(v.GetRaw()) > 0 {
+		parts = append(parts, fmt.Sprintf("<%d bytes>", len(v.GetRaw())))
+	}
+	if s := formatRepeatedValue(v.GetArray()); s != "" {
+		parts = append(parts, s)
+	}
+	if s := formatRepeatedValue(v.GetArrayAppend()); s != "" {
+		parts = append(parts, "+"+s)
+	}
+	if s := formatRepeatedValue(v.GetArrayReduce()); s != "" {
+		parts = append(parts, "-"+s)
+	}
+	if len(parts) == 0 && typed {
+		switch dataType {
+		case proto.Property_Boolean:
+			return "false"
+		case proto.Property_Number, proto.Property_Float, proto.Property_Amount:
+			return "0"
+		}
+	}
+	if len(parts) == 0 {
+		return "<empty>"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatRepeatedValue(v *proto.RepeatedValue) string {
+	switch {
+	case len(v.GetStrings()) > 0:
+		return fmt.Sprintf("%q", v.GetStrings())
+	case len(v.GetInts()) > 0:
+		return fmt.Sprintf("%v", v.GetInts())
+	case len(v.GetKeyValue()) > 0:
+		return formatBytesMap(v.GetKeyValue())
+	}
+	return ""
+}
+
+func formatStringMap(m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+m[k])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func formatBytesMap(m map[string][]byte) string {
+	sm := make(map[string]string, len(m))
+	for k, v := range m {
+		sm[k] = string(v)
+	}
+	return formatStringMap(sm)
+}
@@ -0,0 +1,121 @@
+package keystone
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestRenderMutatePlan(t *testing.T) {
+	req := &proto.MutateRequest{
+		EntityId: "abc",
+		Schema:   &proto.Key{Key: "customer"},
+		Mutation: &proto.Mutation{
+			Properties: []*proto.EntityProperty{
+				{Property: "name", Value: &proto.Value{Text: "new"}},
+				{Property: "age", Value: &proto.Value{Int: 30}},
+			},
+			RemoveLabels: []*proto.EntityLabel{{Name: "env", Value: "dev"}},
+		},
+	}
+	previous := &proto.EntityResponse{Properties: []*proto.EntityProperty{
+		{Property: "name", Value: &proto.Value{Text: "old"}},
+	}}
+
+	plan := RenderMutatePlan(req, previous)
+	for _, expect := range []string{`~ name: "old" => "new"`, "+ age: 30", "- env=dev"} {
+		if !strings.Contains(plan, expect) {
+			t.Errorf("Expected plan to contain %q, got\n%s", expect, plan)
+		}
+	}
+}
+
+type testPlanSeries struct {
+	TimeSeriesEntity
+	BaseEntity
+	Reading int64
+}
+
+func TestDryRun(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	requests := 0
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		requests++
+		return req.GetSchema(), nil
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		requests++
+		return &proto.MutateResponse{Success: true}, nil
+	}
+	server.ReportTimeSeriesFunc = func(_ context.Context, req *proto.ReportTimeSeriesRequest) (*proto.MutateResponse, error) {
+		requests++
+		return &proto.MutateResponse{Success: true}, nil
+	}
+
+	ctx := context.Background()
+	actor := conn.Actor("workspace", "", "", "")
+
+	// the before mutate hook aborts, so it must not be run
+	planned, err := actor.PlanMutate(ctx, &testHookEntity{Name: "planned"}, "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planned.GetMutation().GetProperties()[0].GetValue().GetText() != "planned" {
+		t.Error("Expected the plan to hold the mutation, got", planned)
+	}
+	if !strings.Contains(RenderMutatePlan(planned, nil), `+ name: "planned"`) {
+		t.Error("Expected the planned request to render, got", RenderMutatePlan(planned, nil))
+	}
+
+	remote := RemoteEntity("abc")
+	remote.LogInfo("staged", "", "", "", nil)
+	remotePlan := &MutatePlan{}
+	if err := actor.RemoteMutate(ctx, remote, "", DryRun(remotePlan)); err != nil {
+		t.Fatal(err)
+	}
+	if len(remotePlan.Request.GetMutation().GetLogs()) != 1 || len(remote.GetKeystoneLogs()) != 1 {
+		t.Error("Expected the log to be planned and kept staged")
+	}
+
+	series := &testPlanSeries{Reading: 3}
+	seriesPlan := &MutatePlan{}
+	if err := actor.ReportTimeSeries(ctx, series, DryRun(seriesPlan)); err != nil {
+		t.Fatal(err)
+	}
+	if seriesPlan.Request == nil || seriesPlan.Schema == nil {
+		t.Error("Expected the time series to be planned, got", seriesPlan)
+	}
+
+	if requests != 0 {
+		t.Error("Expected a dry run to send no requests, got", requests)
+	}
+}
+
+func TestRenderMutatePlanTypedZero(t *testing.T) {
+	plan := &MutatePlan{
+		Request: &proto.MutateRequest{Mutation: &proto.Mutation{Properties: []*proto.EntityProperty{
+			{Property: "active", Value: &proto.Value{}},
+			{Property: "count", Value: &proto.Value{}},
+			{Property: "name", Value: &proto.Value{}},
+		}}},
+		Previous: &proto.EntityResponse{Properties: []*proto.EntityProperty{
+			{Property: "active", Value: &proto.Value{Bool: true}},
+		}},
+		Schema: &proto.Schema{Properties: []*proto.Property{
+			{Name: "active", DataType: proto.Property_Boolean},
+			{Name: "count", DataType: proto.Property_Number},
+		}},
+	}
+
+	rendered := plan.String()
+	for _, expect := range []string{"~ active: true => false", "+ count: 0", "+ name: <empty>"} {
+		if !strings.Contains(rendered, expect) {
+			t.Errorf("Expected plan to contain %q, got\n%s", expect, rendered)
+		}
+	}
+}
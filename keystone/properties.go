@@ -2,8 +2,10 @@ package keystone
 
 import (
 	"context"
+	"errors"
 	"github.com/kubex/keystone-go/proto"
 	"reflect"
+	"slices"
 )
 
 func (a *Actor) SetDynamicProperties(ctx context.Context, entityID string, setProperties []*proto.EntityProperty, removeProperties []string, comment string) error {
@@ -45,6 +47,102 @@ func (a *Actor) GetDynamicProperties(ctx context.Context, entityID string, prope
 	return res, nil
 }
 
+// SetDynamicPropertiesFrom sets the dynamic properties from the fields of src, removing those set to their zero value
+// Fields tagged omitempty are left unchanged when empty
+func (a *Actor) SetDynamicPropertiesFrom(ctx context.Context, entityID string, src interface{}, comment string) error {
+	srcType := reflect.TypeOf(src)
+	for srcType != nil && srcType.Kind() == reflect.Pointer {
+		srcType = srcType.Elem()
+	}
+	if srcType == nil || srcType.Kind() != reflect.Struct {
+		return errors.New("src must be a struct or struct pointer")
+	}
+	if srcValue := reflect.ValueOf(src); srcValue.Kind() == reflect.Pointer && srcValue.IsNil() {
+		return errors.New("src must be a struct or struct pointer")
+	}
+
+	names := dynamicPropertyNames(srcType, "", true)
+	encoder := &PropertyEncoder{}
+	var setProperties []*proto.EntityProperty
+	set := make(map[string]bool)
+	for _, prop := range encoder.Marshal(src).GetProperties() {
+		if slices.Contains(names, prop.GetProperty()) {
+			setProperties = append(setProperties, prop)
+			set[prop.GetProperty()] = true
+		}
+	}
+	var removeProperties []string
+	for _, name := range dynamicPropertyNames(srcType, "", false) {
+		if !set[name] {
+			removeProperties = append(removeProperties, name)
+		}
+	}
+
+	return a.SetDynamicProperties(ctx, entityID, setProperties, removeProperties, comment)
+}
+
+// GetDynamicPropertiesInto retrieves the dynamic properties declared by the fields of dst, and hydrates dst with them
+func (a *Actor) GetDynamicPropertiesInto(ctx context.Context, entityID string, dst interface{}) error {
+	dstType := reflect.TypeOf(dst)
+	if dstType == nil || dstType.Kind() != reflect.Pointer || dstType.Elem().Kind() != reflect.Struct {
+		return errors.New("dst must be a struct pointer")
+	}
+
+	m := &proto.EntityRequest{
+		Authorization: a.Authorization(),
+		EntityId:      entityID,
+		View: &proto.EntityView{
+			DynamicProperties: dynamicPropertyNames(dstType.Elem(), "", true),
+		},
+	}
+
+	resp, err := a.connection.Retrieve(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	propertyMap := make(map[string]*proto.EntityProperty, len(resp.GetDynamicProperties()))
+	for _, prop := range resp.GetDynamicProperties() {
+		propertyMap[prop.GetProperty()] = prop
+	}
+	return entityResponseToDst(propertyMap, nil, dst, "")
+}
+
+// dynamicPropertyNames returns the property names of the fields of t, omitempty fields are only included when withOmitEmpty
+func dynamicPropertyNames(t reflect.Type, prefix string, withOmitEmpty bool) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous {
+			if fieldType.Kind() == reflect.Struct {
+				names = append(names, dynamicPropertyNames(fieldType, prefix, withOmitEmpty)...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, prefix)
+		if !fOpt.isProperty() || fOpt.keystoneProvided() {
+			continue
+		}
+
+		if supportedType(field.Type) {
+			if withOmitEmpty || !fOpt.omitempty {
+				names = append(names, fOpt.name)
+			}
+		} else if fieldType.Kind() == reflect.Struct {
+			names = append(names, dynamicPropertyNames(fieldType, fOpt.name+".", withOmitEmpty)...)
+		}
+	}
+	return names
+}
+
 type PropertyValueList map[string]*proto.Value
 
 func (p PropertyValueList) Get(key string) *proto.Value {
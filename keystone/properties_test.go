@@ -0,0 +1,136 @@
+package keystone
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type testDynamicProperties struct {
+	Nickname  string
+	Visits    int64
+	LastSeen  time.Time
+	Balance   Amount
+	Tags      StringSet
+	Meta      map[string]string
+	Note      string `keystone:",omitempty"`
+	Preferred struct {
+		Colour string
+	}
+	EntityID string `keystone:"_entity_id"`
+}
+
+func TestSetDynamicPropertiesFrom(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	var mutation *proto.Mutation
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		mutation = req.GetMutation()
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	src := testDynamicProperties{
+		Nickname: "Bee",
+		LastSeen: time.Unix(1700000000, 0),
+		Balance:  NewAmount("USD", 1250),
+		Meta:     map[string]string{"source": "import"},
+		EntityID: "a",
+	}
+	src.Preferred.Colour = "yellow"
+
+	actor := conn.Actor("workspace", "", "", "")
+	if err := actor.SetDynamicPropertiesFrom(context.Background(), "a", &src, "update"); err != nil {
+		t.Fatal(err)
+	}
+
+	set := map[string]*proto.Value{}
+	for _, prop := range mutation.GetDynamicProperties() {
+		set[prop.GetProperty()] = prop.GetValue()
+	}
+	if _, ok := set["_entity_id"]; ok {
+		t.Error("Expected keystone provided properties not to be set, got", set)
+	}
+	if set["nickname"].GetText() != "Bee" || set["preferred.colour"].GetText() != "yellow" {
+		t.Error("Unexpected text properties", set)
+	}
+	if set["last_seen"].GetTime().AsTime().Unix() != 1700000000 {
+		t.Error("Unexpected time property", set["last_seen"])
+	}
+	if set["balance"].GetText() != "USD" || set["balance"].GetInt() != 1250 {
+		t.Error("Unexpected amount property", set["balance"])
+	}
+	if string(set["meta"].GetArray().GetKeyValue()["source"]) != "import" {
+		t.Error("Unexpected key value property", set["meta"])
+	}
+
+	remove := mutation.GetRemoveDynamicProperties()
+	slices.Sort(remove)
+	if !slices.Equal(remove, []string{"tags", "visits"}) {
+		t.Error("Expected zero value fields to be removed, omitting omitempty fields, got", remove)
+	}
+	if mutation.GetComment() != "update" {
+		t.Error("Expected comment update, got", mutation.GetComment())
+	}
+
+	var nilSrc *testDynamicProperties
+	if err := actor.SetDynamicPropertiesFrom(context.Background(), "a", nilSrc, ""); err == nil {
+		t.Error("Expected a nil struct pointer to be rejected")
+	}
+}
+
+func TestGetDynamicPropertiesInto(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		requested := req.GetView().GetDynamicProperties()
+		if !slices.Contains(requested, "note") || !slices.Contains(requested, "preferred.colour") {
+			t.Error("Expected all fields to be requested, got", requested)
+		}
+		return &proto.EntityResponse{
+			Entity: &proto.Entity{EntityId: req.GetEntityId()},
+			DynamicProperties: []*proto.EntityProperty{
+				{Property: "nickname", Value: &proto.Value{Text: "Bee"}},
+				{Property: "visits", Value: &proto.Value{Int: 3}},
+				{Property: "last_seen", Value: &proto.Value{Time: timestamppb.New(time.Unix(1700000000, 0))}},
+				{Property: "balance", Value: &proto.Value{Text: "GBP", Int: 500}},
+				{Property: "tags", Value: &proto.Value{Array: &proto.RepeatedValue{Strings: []string{"vip", "beta"}}}},
+				{Property: "meta", Value: &proto.Value{Array: &proto.RepeatedValue{KeyValue: map[string][]byte{"source": []byte("import")}}}},
+				{Property: "preferred.colour", Value: &proto.Value{Text: "yellow"}},
+			},
+		}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	dst := testDynamicProperties{}
+	if err := actor.GetDynamicPropertiesInto(context.Background(), "a", &dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Nickname != "Bee" || dst.Visits != 3 || dst.Preferred.Colour != "yellow" {
+		t.Error("Unexpected properties", dst)
+	}
+	if dst.LastSeen.Unix() != 1700000000 {
+		t.Error("Unexpected time", dst.LastSeen)
+	}
+	if dst.Balance.Currency != "GBP" || dst.Balance.Units != 500 {
+		t.Error("Unexpected amount", dst.Balance)
+	}
+	if !dst.Tags.Has("vip") || !dst.Tags.Has("beta") {
+		t.Error("Unexpected tags", dst.Tags.Values())
+	}
+	if dst.Meta["source"] != "import" {
+		t.Error("Unexpected meta", dst.Meta)
+	}
+
+	if err := actor.GetDynamicPropertiesInto(context.Background(), "a", dst); err == nil {
+		t.Error("Expected an error for a non pointer dst")
+	}
+}
@@ -0,0 +1,539 @@
+package keystone
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// QueryError describes invalid query input, Pos is the byte offset the error was found at
+type QueryError struct {
+	Pos     int
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", e.Pos, e.Message)
+}
+
+// ParseQuery compiles a textual query into List options for the registered entity type
+// Values are coerced to the property types of the registered schema
+func (c *Connection) ParseQuery(entityType, query string) ([]FindOption, error) {
+	return ParseQuery(query, c.registeredSchema(entityType))
+}
+
+// ParseFindQuery compiles a textual query into Find options for the registered entity type
+func (c *Connection) ParseFindQuery(entityType, query string) ([]FindOption, error) {
+	return ParseFindQuery(query, c.registeredSchema(entityType))
+}
+
+// ParseQuery compiles a textual query into List options, coercing values to the property types in schema when provided
+// When a schema is provided, filtering or sorting by a property it does not define is an error
+//
+//	status = "active" AND amount >= 100 AND tags IN ("a", "b") AND label:env=prod ORDER BY -created, name LIMIT 10 PAGE 2
+//
+// Supported operators are = != > >= < <= IN, NOT IN, BETWEEN x AND y, CONTAINS, NOT CONTAINS, STARTS WITH and ENDS WITH
+// ORDER BY and LIMIT are only applied by List, use ParseFindQuery for queries passed to Find
+func ParseQuery(query string, schema *proto.Schema) ([]FindOption, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, schema: schema}
+	return p.parse()
+}
+
+// ParseFindQuery compiles a textual query into Find options, as ParseQuery
+// Find does not sort or limit its results, so ORDER BY and LIMIT clauses are an error
+func ParseFindQuery(query string, schema *proto.Schema) ([]FindOption, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, schema: schema, find: true}
+	return p.parse()
+}
+
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryWord
+	queryString
+	queryNumber
+	queryOperator
+	queryOpen
+	queryClose
+	queryComma
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) is(keyword string) bool {
+	return t.kind == queryWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t queryToken) describe() string {
+	switch t.kind {
+	case queryEOF:
+		return "end of query"
+	case queryString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(query); {
+		ch, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(ch):
+			i += size
+		case ch == '(':
+			tokens = append(tokens, queryToken{kind: queryOpen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, queryToken{kind: queryClose, text: ")", pos: i})
+			i++
+		case ch == ',':
+			tokens = append(tokens, queryToken{kind: queryComma, text: ",", pos: i})
+			i++
+		case ch == '"' || ch == '\'':
+			start := i
+			var sb strings.Builder
+			escaped := false
+			for i++; ; {
+				if i >= len(query) {
+					return nil, &QueryError{Pos: start, Message: "unterminated string"}
+				}
+				r, rSize := utf8.DecodeRuneInString(query[i:])
+				i += rSize
+				if escaped {
+					sb.WriteRune(r)
+					escaped = false
+					continue
+				}
+				if r == '\\' {
+					escaped = true
+					continue
+				}
+				if r == ch {
+					break
+				}
+				sb.WriteRune(r)
+			}
+			tokens = append(tokens, queryToken{kind: queryString, text: sb.String(), pos: start})
+		case strings.ContainsRune("=!<>", ch):
+			start := i
+			i++
+			if i < len(query) && query[i] == '=' {
+				i++
+			}
+			op := query[start:i]
+			if op == "!" {
+				return nil, &QueryError{Pos: start, Message: "expected != operator"}
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, queryToken{kind: queryOperator, text: op, pos: start})
+		default:
+			start := i
+			for i < len(query) {
+				r, rSize := utf8.DecodeRuneInString(query[i:])
+				if unicode.IsSpace(r) || strings.ContainsRune("()=,!<>\"'", r) {
+					break
+				}
+				i += rSize
+			}
+			word := query[start:i]
+			kind := queryWord
+			if isQueryNumber(word) {
+				kind = queryNumber
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: word, pos: start})
+		}
+	}
+	return append(tokens, queryToken{kind: queryEOF, pos: len(query)}), nil
+}
+
+// isQueryNumber returns true for numeric literals, which must start with a digit after an optional sign or decimal point
+// so words such as inf and nan remain words
+func isQueryNumber(word string) bool {
+	digits := strings.TrimLeft(word, "+-.")
+	if len(word)-len(digits) > 2 || digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return false
+	}
+	_, err := strconv.ParseFloat(word, 64)
+	return err == nil
+}
+
+type queryParser struct {
+	tokens  []queryToken
+	pos     int
+	schema  *proto.Schema
+	find    bool // the options are for Find, which has no sort or limit
+	options []FindOption
+}
+
+func (p *queryParser) peek() queryToken { return p.tokens[p.pos] }
+
+// peekAt returns the token offset tokens ahead, or the final end of query token
+func (p *queryParser) peekAt(offset int) queryToken {
+	return p.tokens[min(p.pos+offset, len(p.tokens)-1)]
+}
+
+// atOrder returns true at an ORDER BY clause, so properties named order can still be filtered
+func (p *queryParser) atOrder() bool {
+	return p.peek().is("order") && p.peekAt(1).is("by")
+}
+
+// atLimit returns true at a LIMIT clause, so properties named limit can still be filtered
+func (p *queryParser) atLimit() bool {
+	return p.peek().is("limit") && p.peekAt(1).kind == queryNumber
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != queryEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) errorf(t queryToken, format string, args ...any) error {
+	return &QueryError{Pos: t.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) expectKeyword(keyword string) error {
+	if t := p.next(); !t.is(keyword) {
+		return p.errorf(t, "expected %s, found %s", keyword, t.describe())
+	}
+	return nil
+}
+
+func (p *queryParser) parse() ([]FindOption, error) {
+	if p.peek().kind != queryEOF && !p.atOrder() && !p.atLimit() {
+		for {
+			if err := p.parseCondition(); err != nil {
+				return nil, err
+			}
+			if !p.peek().is("and") {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.find && (p.atOrder() || p.atLimit()) {
+		return nil, p.errorf(p.peek(), "%s is not supported by Find, use List", strings.ToUpper(p.peek().text))
+	}
+
+	if p.atOrder() {
+		if err := p.parseOrder(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.atLimit() {
+		if err := p.parseLimit(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t := p.peek(); t.kind != queryEOF {
+		return nil, p.errorf(t, "unexpected %s", t.describe())
+	}
+	return p.options, nil
+}
+
+func (p *queryParser) parseCondition() error {
+	nameToken := p.next()
+	if nameToken.kind != queryWord {
+		return p.errorf(nameToken, "expected property name, found %s", nameToken.describe())
+	}
+
+	if label, ok := strings.CutPrefix(nameToken.text, "label:"); ok {
+		return p.parseLabel(nameToken, label)
+	}
+
+	property := nameToken.text
+	if !p.knownProperty(property) {
+		return p.errorf(nameToken, "unknown property %s", property)
+	}
+	opToken := p.next()
+	switch {
+	case opToken.kind == queryOperator:
+		operator := map[string]proto.Operator{
+			"=":  proto.Operator_Equal,
+			"!=": proto.Operator_NotEqual,
+			">":  proto.Operator_GreaterThan,
+			">=": proto.Operator_GreaterThanOrEqual,
+			"<":  proto.Operator_LessThan,
+			"<=": proto.Operator_LessThanOrEqual,
+		}[opToken.text]
+		return p.addFilter(property, operator, 1)
+	case opToken.is("in"):
+		return p.parseList(property, proto.Operator_In)
+	case opToken.is("between"):
+		return p.parseBetween(property)
+	case opToken.is("contains"):
+		return p.addFilter(property, proto.Operator_Contains, 1)
+	case opToken.is("starts"):
+		if err := p.expectKeyword("with"); err != nil {
+			return err
+		}
+		return p.addFilter(property, proto.Operator_StartsWith, 1)
+	case opToken.is("ends"):
+		if err := p.expectKeyword("with"); err != nil {
+			return err
+		}
+		return p.addFilter(property, proto.Operator_EndsWith, 1)
+	case opToken.is("not"):
+		switch t := p.next(); {
+		case t.is("in"):
+			return p.parseList(property, proto.Operator_NotIn)
+		case t.is("contains"):
+			return p.addFilter(property, proto.Operator_NotContains, 1)
+		default:
+			return p.errorf(t, "expected IN or CONTAINS after NOT, found %s", t.describe())
+		}
+	}
+	return p.errorf(opToken, "expected operator after %s, found %s", property, opToken.describe())
+}
+
+func (p *queryParser) parseLabel(nameToken queryToken, label string) error {
+	if label == "" {
+		return p.errorf(nameToken, "expected label name")
+	}
+	if t := p.next(); t.kind != queryOperator || t.text != "=" {
+		return p.errorf(t, "labels only support =, found %s", t.describe())
+	}
+	valueToken := p.next()
+	if valueToken.kind != queryWord && valueToken.kind != queryString && valueToken.kind != queryNumber {
+		return p.errorf(valueToken, "expected label value, found %s", valueToken.describe())
+	}
+	p.options = append(p.options, WithLabel(label, valueToken.text))
+	return nil
+}
+
+// addFilter reads count values, adding the property filter
+func (p *queryParser) addFilter(property string, operator proto.Operator, count int) error {
+	values := make([]*proto.Value, 0, count)
+	for i := 0; i < count; i++ {
+		value, err := p.parseValue(property)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+	p.options = append(p.options, propertyFilter{key: property, values: values, operator: operator})
+	return nil
+}
+
+func (p *queryParser) parseList(property string, operator proto.Operator) error {
+	if t := p.next(); t.kind != queryOpen {
+		return p.errorf(t, "expected ( to start list, found %s", t.describe())
+	}
+	var values []*proto.Value
+	for {
+		value, err := p.parseValue(property)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == queryClose {
+			break
+		}
+		if t.kind != queryComma {
+			return p.errorf(t, "expected , or ) in list, found %s", t.describe())
+		}
+	}
+	p.options = append(p.options, propertyFilter{key: property, values: values, operator: operator})
+	return nil
+}
+
+func (p *queryParser) parseBetween(property string) error {
+	from, err := p.parseValue(property)
+	if err != nil {
+		return err
+	}
+	if err := p.expectKeyword("and"); err != nil {
+		return err
+	}
+	to, err := p.parseValue(property)
+	if err != nil {
+		return err
+	}
+	p.options = append(p.options, propertyFilter{key: property, values: []*proto.Value{from, to}, operator: proto.Operator_Between})
+	return nil
+}
+
+func (p *queryParser) parseOrder() error {
+	p.next()
+	if err := p.expectKeyword("by"); err != nil {
+		return err
+	}
+	for {
+		t := p.next()
+		if t.kind != queryWord {
+			return p.errorf(t, "expected sort property, found %s", t.describe())
+		}
+		property, descending := strings.CutPrefix(t.text, "-")
+		if !p.knownProperty(property) && !entitySortable(property) {
+			return p.errorf(t, "unknown sort property %s", property)
+		}
+		if p.peek().is("desc") {
+			p.next()
+			descending = true
+		} else if p.peek().is("asc") {
+			p.next()
+		}
+		p.options = append(p.options, SortByDirection(property, descending))
+
+		if p.peek().kind != queryComma {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *queryParser) parseLimit() error {
+	p.next()
+	perPage, err := p.parseInt("limit")
+	if err != nil {
+		return err
+	}
+	pageNumber := int32(1)
+	if p.peek().is("page") {
+		p.next()
+		if pageNumber, err = p.parseInt("page"); err != nil {
+			return err
+		}
+	}
+	p.options = append(p.options, Limit(perPage, pageNumber))
+	return nil
+}
+
+func (p *queryParser) parseInt(what string) (int32, error) {
+	t := p.next()
+	n, err := strconv.ParseInt(t.text, 10, 32)
+	if t.kind != queryNumber || err != nil || n < 1 {
+		return 0, p.errorf(t, "expected positive whole number for %s, found %s", what, t.describe())
+	}
+	return int32(n), nil
+}
+
+// parseValue reads a literal, coercing it to the schema type of the property
+func (p *queryParser) parseValue(property string) (*proto.Value, error) {
+	t := p.next()
+	if t.kind != queryWord && t.kind != queryString && t.kind != queryNumber {
+		return nil, p.errorf(t, "expected value for %s, found %s", property, t.describe())
+	}
+
+	dataType, known := p.propertyType(property)
+	if !known {
+		// without a schema, or for keystone properties, the value is typed by its literal
+		return inferQueryValue(t), nil
+	}
+
+	switch dataType {
+	case proto.Property_Amount:
+		amount, ok := parseQueryAmount(t.text)
+		if !ok {
+			return nil, p.errorf(t, "%s expects an amount in minor units such as \"USD 1250\", found %s", property, t.describe())
+		}
+		return amount.ToProtoValue(), nil
+	case proto.Property_Number, proto.Property_Ints, proto.Property_IntSet:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, p.errorf(t, "%s expects a whole number, found %s", property, t.describe())
+		}
+		return &proto.Value{Int: n}, nil
+	case proto.Property_Float:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "%s expects a number, found %s", property, t.describe())
+		}
+		return &proto.Value{Float: f}, nil
+	case proto.Property_Boolean:
+		b, err := strconv.ParseBool(t.text)
+		if err != nil {
+			return nil, p.errorf(t, "%s expects true or false, found %s", property, t.describe())
+		}
+		return &proto.Value{Bool: b}, nil
+	case proto.Property_Time:
+		for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+			if tm, err := time.Parse(layout, t.text); err == nil {
+				return &proto.Value{Time: timestamppb.New(tm)}, nil
+			}
+		}
+		return nil, p.errorf(t, "%s expects a time such as 2006-01-02, found %s", property, t.describe())
+	}
+	return &proto.Value{Text: t.text}, nil
+}
+
+// parseQueryAmount reads an amount as whole minor units, optionally with a currency code before or after the units
+func parseQueryAmount(text string) (Amount, bool) {
+	var amount Amount
+	units := 0
+	parts := strings.Fields(text)
+	for _, part := range parts {
+		if n, err := strconv.ParseInt(part, 10, 64); err == nil {
+			amount.Units = n
+			units++
+		} else if strings.IndexFunc(part, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+			amount.Currency = strings.ToUpper(part)
+		}
+	}
+	return amount, units == 1 && (len(parts) == 1 || (len(parts) == 2 && amount.Currency != ""))
+}
+
+// knownProperty returns true when there is no schema to check against, or the schema defines the property
+// Properties prefixed with _ are provided by keystone rather than the schema
+func (p *queryParser) knownProperty(property string) bool {
+	if p.schema == nil || strings.HasPrefix(property, "_") {
+		return true
+	}
+	_, known := p.propertyType(property)
+	return known
+}
+
+func (p *queryParser) propertyType(property string) (proto.Property_Type, bool) {
+	for _, prop := range p.schema.GetProperties() {
+		if prop.GetName() == property {
+			return prop.GetDataType(), true
+		}
+	}
+	return proto.Property_Text, false
+}
+
+// inferQueryValue types a literal when the property is not in the schema
+func inferQueryValue(t queryToken) *proto.Value {
+	switch t.kind {
+	case queryNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &proto.Value{Int: n}
+		}
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return &proto.Value{Float: f}
+	case queryWord:
+		if b, err := strconv.ParseBool(t.text); err == nil && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")) {
+			return &proto.Value{Bool: b}
+		}
+	}
+	return &proto.Value{Text: t.text}
+}
@@ -0,0 +1,143 @@
+package keystone
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestParseQuery(t *testing.T) {
+	schema := &proto.Schema{Properties: []*proto.Property{
+		{Name: "status", DataType: proto.Property_Text},
+		{Name: "amount", DataType: proto.Property_Number},
+		{Name: "code", DataType: proto.Property_Text},
+		{Name: "tags", DataType: proto.Property_Strings},
+		{Name: "name", DataType: proto.Property_Text},
+	}}
+
+	options, err := ParseQuery(`status = "active" AND amount >= 100 AND tags IN ("a","b") AND label:env=prod AND code = 42 ORDER BY -created, name LIMIT 10 PAGE 2`, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fReq := &filterRequest{}
+	for _, opt := range options {
+		opt.Apply(fReq)
+	}
+
+	if len(fReq.Filters) != 4 {
+		t.Fatal("Expected 4 property filters, got", len(fReq.Filters))
+	}
+	if f := fReq.Filters[0]; f.GetProperty() != "status" || f.GetOperator() != proto.Operator_Equal || f.GetValues()[0].GetText() != "active" {
+		t.Error("Unexpected status filter", f)
+	}
+	if f := fReq.Filters[1]; f.GetOperator() != proto.Operator_GreaterThanOrEqual || f.GetValues()[0].GetInt() != 100 {
+		t.Error("Unexpected amount filter", f)
+	}
+	if f := fReq.Filters[2]; f.GetOperator() != proto.Operator_In || len(f.GetValues()) != 2 || f.GetValues()[1].GetText() != "b" {
+		t.Error("Unexpected tags filter", f)
+	}
+	if f := fReq.Filters[3]; f.GetValues()[0].GetText() != "42" {
+		t.Error("Expected code to be coerced to text, got", f)
+	}
+	if len(fReq.Labels) != 1 || fReq.Labels[0].GetName() != "env" || fReq.Labels[0].GetValue() != "prod" {
+		t.Error("Unexpected labels", fReq.Labels)
+	}
+	if len(fReq.Sort) != 2 || !fReq.Sort[0].GetDescending() || fReq.Sort[0].GetProperty() != "created" || fReq.Sort[1].GetProperty() != "name" {
+		t.Error("Unexpected sort", fReq.Sort)
+	}
+	if fReq.PerPage != 10 || fReq.PageNumber != 2 {
+		t.Error("Unexpected limit", fReq.PerPage, fReq.PageNumber)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	schema := &proto.Schema{Properties: []*proto.Property{
+		{Name: "amount", DataType: proto.Property_Number},
+		{Name: "status", DataType: proto.Property_Text},
+		{Name: "tags", DataType: proto.Property_Strings},
+	}}
+	tests := []struct {
+		query string
+		pos   int
+		find  bool
+	}{
+		{`amount >= "lots"`, 10, false},
+		{`status = "active`, 9, false},
+		{`status "active"`, 7, false},
+		{`tags IN ("a" "b")`, 13, false},
+		{`status = active AND`, 19, false},
+		{`status = active AND colour = red`, 20, false},
+		{`status = active ORDER BY colour`, 25, false},
+		{`status = active ORDER BY amount`, 16, true},
+		{`status = active LIMIT 5`, 16, true},
+	}
+	for _, test := range tests {
+		parse := ParseQuery
+		if test.find {
+			parse = ParseFindQuery
+		}
+		_, err := parse(test.query, schema)
+		var qErr *QueryError
+		if !errors.As(err, &qErr) {
+			t.Errorf("Expected query error for %q, got %v", test.query, err)
+			continue
+		}
+		if qErr.Pos != test.pos {
+			t.Errorf("Expected error at %d for %q, got %v", test.pos, test.query, qErr)
+		}
+	}
+}
+
+func TestParseQueryLiterals(t *testing.T) {
+	schema := &proto.Schema{Properties: []*proto.Property{
+		{Name: "price", DataType: proto.Property_Amount},
+		{Name: "order", DataType: proto.Property_Number},
+		{Name: "limit", DataType: proto.Property_Number},
+		{Name: "name", DataType: proto.Property_Text},
+		{Name: "mode", DataType: proto.Property_Text},
+		{Name: "café", DataType: proto.Property_Text},
+	}}
+
+	options, err := ParseQuery(`name = café AND price >= "GBP 500" AND price < 900 AND order = 3 AND limit > 1 AND mode = inf ORDER BY order LIMIT 5`, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fReq := &filterRequest{}
+	for _, opt := range options {
+		opt.Apply(fReq)
+	}
+
+	if len(fReq.Filters) != 6 {
+		t.Fatal("Expected 6 property filters, got", len(fReq.Filters))
+	}
+	if v := fReq.Filters[0].GetValues()[0]; v.GetText() != "café" {
+		t.Error("Expected multi byte words to be read whole, got", v)
+	}
+	if v := fReq.Filters[1].GetValues()[0]; v.GetText() != "GBP" || v.GetInt() != 500 {
+		t.Error("Expected an amount with currency, got", v)
+	}
+	if v := fReq.Filters[2].GetValues()[0]; v.GetText() != "" || v.GetInt() != 900 {
+		t.Error("Expected an amount without currency, got", v)
+	}
+	if f := fReq.Filters[3]; f.GetProperty() != "order" || f.GetValues()[0].GetInt() != 3 {
+		t.Error("Expected order to be filtered as a property, got", f)
+	}
+	if f := fReq.Filters[4]; f.GetProperty() != "limit" || f.GetValues()[0].GetInt() != 1 {
+		t.Error("Expected limit to be filtered as a property, got", f)
+	}
+	if v := fReq.Filters[5].GetValues()[0]; v.GetText() != "inf" {
+		t.Error("Expected inf to remain text, got", v)
+	}
+	if len(fReq.Sort) != 1 || fReq.Sort[0].GetProperty() != "order" || fReq.PerPage != 5 {
+		t.Error("Unexpected order and limit", fReq.Sort, fReq.PerPage)
+	}
+
+	if _, err := ParseQuery(`price = "GBP"`, schema); err == nil {
+		t.Error("Expected an amount without units to fail")
+	}
+	if _, err := ParseQuery(`café = "x" AND`, schema); err == nil || err.(*QueryError).Pos != 15 {
+		t.Error("Expected the error position as a byte offset, got", err)
+	}
+}
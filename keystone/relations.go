@@ -0,0 +1,209 @@
+package keystone
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// DefaultMaxRelationDepth is how many levels of rel= fields are eagerly loaded, stopping relationship cycles
+const DefaultMaxRelationDepth = 3
+
+// SetMaxRelationDepth sets how many levels of rel= fields are eagerly loaded, zero restores the default
+func (c *Connection) SetMaxRelationDepth(depth int) {
+	c.maxRelationDepth.Store(int64(depth))
+}
+
+func (c *Connection) relationDepth() int {
+	if depth := int(c.maxRelationDepth.Load()); depth > 0 {
+		return depth
+	}
+	return DefaultMaxRelationDepth
+}
+
+// relationField is a struct field tagged with rel= to receive the targets of a relationship
+type relationField struct {
+	index    []int
+	relation string
+	target   reflect.Type
+	slice    bool
+	pointer  bool
+}
+
+// relationFields returns the rel= fields of t, including those of embedded structs
+func relationFields(t reflect.Type) []relationField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []relationField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && embedded.Kind() == reflect.Struct {
+			for _, embedded := range relationFields(embedded) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, "")
+		if fOpt.relation == "" {
+			continue
+		}
+
+		rf := relationField{index: []int{i}, relation: fOpt.relation, target: field.Type}
+		if rf.target.Kind() == reflect.Slice {
+			rf.slice = true
+			rf.target = rf.target.Elem()
+		}
+		if rf.target.Kind() == reflect.Pointer {
+			rf.pointer = true
+			rf.target = rf.target.Elem()
+		}
+		if rf.target.Kind() == reflect.Struct {
+			fields = append(fields, rf)
+		}
+	}
+	return fields
+}
+
+// relationFieldValue returns the field at index, allocating nil embedded struct pointers on the way
+func relationFieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					if !v.CanSet() {
+						return reflect.Value{}, false
+					}
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// relationsLoader requests the relationships needed to populate the rel= fields of t, or returns nil when there are none
+func relationsLoader(t reflect.Type) RetrieveOption {
+	fields := relationFields(t)
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.relation)
+	}
+	return WithRelationships(keys...)
+}
+
+// loadRelations populates the rel= fields of each dst, fetching the targets across all entities in a batch per field
+func (a *Actor) loadRelations(ctx context.Context, resps []*proto.EntityResponse, dsts []reflect.Value, depth int) error {
+	if len(dsts) == 0 || depth >= a.connection.relationDepth() {
+		return nil
+	}
+
+	for _, field := range relationFields(dsts[0].Type()) {
+		var targetIDs []string
+		seen := make(map[string]bool)
+		entityTargets := make([][]string, len(resps))
+		for i, resp := range resps {
+			for _, rel := range resp.GetRelationships() {
+				if rel.GetRelationship().GetKey() != field.relation || rel.GetTargetId() == "" {
+					continue
+				}
+				entityTargets[i] = append(entityTargets[i], rel.GetTargetId())
+				if !seen[rel.GetTargetId()] {
+					seen[rel.GetTargetId()] = true
+					targetIDs = append(targetIDs, rel.GetTargetId())
+				}
+			}
+		}
+		if len(targetIDs) == 0 {
+			continue
+		}
+
+		targets, err := a.fetchRelated(ctx, field.target, targetIDs, depth+1)
+		if err != nil {
+			return err
+		}
+
+		for i, dst := range dsts {
+			fieldValue, ok := relationFieldValue(reflect.Indirect(dst), field.index)
+			if !ok {
+				continue
+			}
+			// replace rather than add to targets already held by a reused dst
+			fieldValue.SetZero()
+			for _, targetID := range entityTargets[i] {
+				target, ok := targets[targetID]
+				if !ok {
+					continue
+				}
+				if !field.pointer {
+					target = target.Elem()
+				}
+				if field.slice {
+					fieldValue.Set(reflect.Append(fieldValue, target))
+				} else {
+					fieldValue.Set(target)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fetchRelated retrieves the target entities by ID, returning pointers to t keyed by entity ID
+func (a *Actor) fetchRelated(ctx context.Context, t reflect.Type, targetIDs []string, depth int) (map[string]reflect.Value, error) {
+	sample := reflect.New(t).Interface()
+	schema, viewName, err := a.connection.schemaFor(sample)
+	if err != nil {
+		return nil, err
+	}
+	retrieve := []RetrieveOption{ViewFrom(sample)}
+	if viewName != "" {
+		retrieve = append(retrieve, WithView(viewName))
+	}
+	if rel := relationsLoader(t); rel != nil && depth < a.connection.relationDepth() {
+		retrieve = append(retrieve, rel)
+	}
+
+	var resps []*proto.EntityResponse
+	var dsts []reflect.Value
+	targets := make(map[string]reflect.Value, len(targetIDs))
+	batchSize := a.connection.batchSize()
+	for start := 0; start < len(targetIDs); start += batchSize {
+		batch := targetIDs[start:min(start+batchSize, len(targetIDs))]
+		resp, err := a.find(ctx, schema.GetType(), RetrieveOptions(retrieve...), WithEntityIDs(batch...))
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range resp.GetEntities() {
+			dst := reflect.New(t)
+			if err := unmarshal(ctx, a, entity, dst.Interface()); err != nil {
+				return nil, err
+			}
+			targets[entity.GetEntity().GetEntityId()] = dst
+			resps = append(resps, entity)
+			dsts = append(dsts, dst)
+		}
+	}
+
+	return targets, a.loadRelations(ctx, resps, dsts, depth)
+}
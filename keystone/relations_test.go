@@ -0,0 +1,122 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testRelCompany struct {
+	BaseEntity
+	Name  string
+	Owner *testRelPerson `keystone:"rel=owner"`
+}
+
+type testRelPerson struct {
+	BaseEntity
+	Name     string
+	Employer *testRelCompany `keystone:"rel=employer"`
+}
+
+func TestEagerRelations(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		for _, p := range req.GetSchema().GetProperties() {
+			if p.GetName() != "name" {
+				t.Error("Expected relation fields to be excluded from the schema, got", p.GetName())
+			}
+		}
+		return req.GetSchema(), nil
+	}
+
+	// p1 works for c1, which is owned by p2 who works for c1, forming a cycle
+	entities := map[string]*proto.EntityResponse{
+		"p1": {Entity: &proto.Entity{EntityId: "p1"}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ann"}}},
+			Relationships: []*proto.EntityRelationship{{Relationship: &proto.Key{Key: "employer"}, TargetId: "c1"}}},
+		"p2": {Entity: &proto.Entity{EntityId: "p2"}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Bob"}}},
+			Relationships: []*proto.EntityRelationship{{Relationship: &proto.Key{Key: "employer"}, TargetId: "c1"}}},
+		"c1": {Entity: &proto.Entity{EntityId: "c1"}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Acme"}}},
+			Relationships: []*proto.EntityRelationship{{Relationship: &proto.Key{Key: "owner"}, TargetId: "p2"}}},
+	}
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		if len(req.GetView().GetRelationshipByType()) != 1 || req.GetView().GetRelationshipByType()[0].GetKey() != "employer" {
+			t.Error("Expected the employer relationship to be requested, got", req.GetView().GetRelationshipByType())
+		}
+		return entities[req.GetEntityId()], nil
+	}
+	finds := 0
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		finds++
+		resp := &proto.FindResponse{}
+		for _, id := range req.GetEntityIds() {
+			resp.Entities = append(resp.Entities, entities[id])
+		}
+		return resp, nil
+	}
+
+	conn.SetMaxRelationDepth(3)
+
+	actor := conn.Actor("workspace", "", "", "")
+	person := &testRelPerson{}
+	if err := actor.GetByID(context.Background(), "p1", person); err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Employer == nil || person.Employer.Name != "Acme" {
+		t.Fatal("Expected employer to be loaded, got", person.Employer)
+	}
+	if person.Employer.Owner == nil || person.Employer.Owner.Name != "Bob" {
+		t.Fatal("Expected employer owner to be loaded, got", person.Employer.Owner)
+	}
+	if person.Employer.Owner.Employer == nil || person.Employer.Owner.Employer.Owner != nil {
+		t.Error("Expected loading to stop at the depth limit")
+	}
+	if finds != 3 {
+		t.Error("Expected a find per relation level, got", finds)
+	}
+}
+
+type testRelMembers struct {
+	Members []testRelPerson `keystone:"rel=member"`
+}
+
+type testRelTeam struct {
+	BaseEntity
+	*testRelMembers
+	Name string
+}
+
+func TestEagerRelations_EmbeddedPointer(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		return &proto.EntityResponse{
+			Entity:        &proto.Entity{EntityId: req.GetEntityId()},
+			Relationships: []*proto.EntityRelationship{{Relationship: &proto.Key{Key: "member"}, TargetId: "p1"}},
+		}, nil
+	}
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		return &proto.FindResponse{Entities: []*proto.EntityResponse{{Entity: &proto.Entity{EntityId: "p1"},
+			Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ann"}}}}}}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	team := &testRelTeam{testRelMembers: &testRelMembers{}}
+	for i := 0; i < 2; i++ {
+		if err := actor.GetByID(context.Background(), "t1", team); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(team.Members) != 1 || team.Members[0].Name != "Ann" {
+		t.Error("Expected the embedded pointer members to be loaded once into the reused dst, got", team.testRelMembers)
+	}
+}
@@ -8,16 +8,23 @@ import (
 	"reflect"
 )
 
-// ReportTimeSeries writes point in time data
-func (a *Actor) ReportTimeSeries(ctx context.Context, src interface{}) error {
+// ReportTimeSeries writes point in time data, only options changing how the request is sent e.g. IdempotencyKey or DryRun are applied
+func (a *Actor) ReportTimeSeries(ctx context.Context, src interface{}, options ...MutateOption) error {
 	if reflect.TypeOf(src).Kind() != reflect.Pointer {
 		return errors.New("mutate requires a pointer to a struct")
 	}
 
-	schema, registered := a.connection.registerType(src)
-	if !registered {
-		// wait for the type to be registered with the keystone server
-		a.connection.SyncSchema().Wait()
+	settings := getMutateSettings(options)
+	var schema *proto.Schema
+	if settings.dryRun {
+		// a dry run has no side effects, so hooks are not run and the schema is not defined
+		schema = a.connection.plannedSchema(src)
+	} else {
+		if err := beforeMutate(ctx, a, src); err != nil {
+			return err
+		}
+
+		schema = a.connection.definedSchema(src)
 	}
 
 	var inputTime *timestamppb.Timestamp
@@ -36,9 +43,7 @@ func (a *Actor) ReportTimeSeries(ctx context.Context, src interface{}) error {
 		return errors.New("you must pass a TimeSeriesEntity as the source")
 	}
 
-	if entityWithLabels, ok := src.(EntityLabelProvider); ok {
-		mutation.Labels = entityWithLabels.GetKeystoneLabels()
-	}
+	applyLabelChanges(mutation, src)
 
 	/*
 		if entityWithSensor, ok := src.(EntitySensorProvider); ok {
@@ -59,7 +64,26 @@ func (a *Actor) ReportTimeSeries(ctx context.Context, src interface{}) error {
 		Timestamp:     inputTime,
 	}
 
-	mResp, err := a.connection.ReportTimeSeries(ctx, m)
+	if err := a.connection.validateTimestamps(mutation); err != nil {
+		return err
+	}
+	if err := a.connection.checkTimestamp("time series input", inputTime); err != nil {
+		return err
+	}
+
+	if settings.dryRun {
+		settings.plan.fill(&proto.MutateRequest{
+			Authorization: m.GetAuthorization(),
+			EntityId:      m.GetEntityId(),
+			Schema:        m.GetSchema(),
+			Mutation:      m.GetMutation(),
+		}, src, schema)
+		return nil
+	}
+
+	mResp, err := a.connection.sendIdempotent(ctx, a.workspaceID, settings, func(ctx context.Context) (*proto.MutateResponse, error) {
+		return a.connection.ReportTimeSeries(ctx, m)
+	})
 
 	if err == nil && mResp.Success {
 		if rawEntity, ok := src.(Entity); ok && entityID == "" {
@@ -67,5 +91,12 @@ func (a *Actor) ReportTimeSeries(ctx context.Context, src interface{}) error {
 		}
 	}
 
-	return mutateToError(mResp, err)
+	if err := mutateToError(mResp, err); err != nil {
+		return err
+	}
+
+	if !settings.keepStaged {
+		clearStaged(src, mutation)
+	}
+	return afterMutate(ctx, a, src)
 }
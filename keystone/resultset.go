@@ -0,0 +1,64 @@
+package keystone
+
+import (
+	"context"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// ResultSet is a page of entities along with the metadata returned by keystone
+type ResultSet struct {
+	Entities     []*proto.EntityResponse
+	TotalResults int32
+	ResultID     string // Identifies the find result for follow up requests
+	LastID       string // Cursor for the next list page, see After
+	Suggestions  []string
+	Warnings     []string
+
+	actor *Actor
+}
+
+// FindWithMeta returns the entities matching the given options, along with the result metadata
+func (a *Actor) FindWithMeta(ctx context.Context, entityType string, retrieve RetrieveOption, options ...FindOption) (*ResultSet, error) {
+	resp, err := a.find(ctx, entityType, retrieve, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultSet{
+		Entities:     resp.GetEntities(),
+		TotalResults: resp.GetTotalResults(),
+		ResultID:     resp.GetResultId(),
+		Suggestions:  resp.GetExtended().GetSuggestions(),
+		Warnings:     resp.GetExtended().GetErrors(),
+		actor:        a,
+	}, nil
+}
+
+// ListWithMeta returns the entities within an active set, along with the result metadata
+func (a *Actor) ListWithMeta(ctx context.Context, entityType string, retrieveProperties []string, options ...FindOption) (*ResultSet, error) {
+	resp, err := a.list(ctx, entityType, retrieveProperties, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultSet{
+		Entities:     resp.GetEntities(),
+		TotalResults: resp.GetTotalResults(),
+		LastID:       resp.GetLastId(),
+		Suggestions:  resp.GetExtended().GetSuggestions(),
+		Warnings:     resp.GetExtended().GetErrors(),
+		actor:        a,
+	}, nil
+}
+
+// HasWarnings returns true when keystone reported problems with the request
+func (r *ResultSet) HasWarnings() bool { return len(r.Warnings) > 0 }
+
+// UnmarshalAppend appends the entities to the slice pointed to by dstPtr
+func (r *ResultSet) UnmarshalAppend(dstPtr any) error {
+	return UnmarshalAppend(dstPtr, r.Entities...)
+}
+
+// Results decodes the entities in the result set as T, preserving the result order
+func Results[T any](ctx context.Context, r *ResultSet) ([]*T, error) {
+	return unmarshalAll[T](ctx, r.actor, r.Entities)
+}
@@ -0,0 +1,42 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestActor_FindWithMeta(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		return &proto.FindResponse{
+			Entities: []*proto.EntityResponse{
+				{Entity: &proto.Entity{EntityId: "a"}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ant"}}}},
+			},
+			TotalResults: 12,
+			ResultId:     "result",
+			Extended:     &proto.ExtendedResponse{Errors: []string{"unknown property"}, Suggestions: []string{"name"}},
+		}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	rs, err := actor.FindWithMeta(context.Background(), "test-typed-entity", WithProperties("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.TotalResults != 12 || rs.ResultID != "result" || !rs.HasWarnings() || rs.Suggestions[0] != "name" {
+		t.Error("Unexpected result metadata", rs)
+	}
+
+	result, err := Results[testTypedEntity](context.Background(), rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Name != "Ant" {
+		t.Error("Expected decoded entity Ant, got", result)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/kubex/keystone-go/proto"
+	"reflect"
 )
 
 // Actor is a struct that represents an actor
@@ -75,7 +76,11 @@ func (a *Actor) GetByUniqueProperty(ctx context.Context, uniqueId, propertyName
 func (a *Actor) Get(ctx context.Context, retrieveBy RetrieveBy, dst interface{}, retrieve ...RetrieveOption) error {
 	entityRequest := retrieveBy.BaseRequest()
 	entityRequest.Authorization = a.Authorization()
+	useCache := true
 	for _, rOpt := range retrieve {
+		if _, ok := rOpt.(skipCache); ok {
+			useCache = false
+		}
 		rOpt.Apply(entityRequest.View)
 		if reOpt, ok := rOpt.(RetrieveEntityOption); ok {
 			reOpt.ApplyRequest(entityRequest)
@@ -88,21 +93,9 @@ func (a *Actor) Get(ctx context.Context, retrieveBy RetrieveBy, dst interface{},
 		return errors.New("invalid retrieveBy and dst combination")
 	}
 
-	view := entityRequest.View
-
-	// set source
-	for _, p := range view.Properties {
-		p.Source = a.Authorization().GetSource()
-	}
-
-	for _, r := range view.RelationshipByType {
-		r.Source = a.Authorization().GetSource()
-	}
-
-	schema, registered := a.connection.registerType(dst)
-	if !registered {
-		// wait for the type to be registered with the keystone server
-		a.connection.SyncSchema().Wait()
+	schema, err := a.prepareView(entityRequest.View, dst)
+	if err != nil {
+		return err
 	}
 	entityRequest.Schema = &proto.Key{Key: schema.GetType(), Source: a.Authorization().Source}
 
@@ -114,14 +107,10 @@ func (a *Actor) Get(ctx context.Context, retrieveBy RetrieveBy, dst interface{},
 		entityRequest.UniqueId.SchemaId = schemaID
 	}
 
-	resp, err := a.connection.Retrieve(ctx, entityRequest)
+	resp, err := a.connection.retrieveCached(ctx, entityRequest, useCache)
 	if err != nil {
 		return err
 	}
-	if be, ok := dst.(BaseEntity); ok {
-		be._lastLoad = resp
-	}
-
 	if lk, ok := dst.(EntityLocker); ok && resp.GetLock() != nil {
 		LockData := &EntityLockInfo{
 			LockAcquired: resp.GetLock().GetLockAcquired(),
@@ -136,11 +125,53 @@ func (a *Actor) Get(ctx context.Context, retrieveBy RetrieveBy, dst interface{},
 		return UnmarshalGeneric(resp, gr)
 	}
 
-	return Unmarshal(resp, dst)
+	if err := unmarshal(ctx, a, resp, dst); err != nil {
+		return err
+	}
+	return a.loadRelations(ctx, []*proto.EntityResponse{resp}, []reflect.Value{reflect.ValueOf(dst)}, 0)
+}
+
+// prepareView adds the datum and related entities dst declares to the view, sets the property source,
+// and selects the named view for projection structs, returning the schema dst is read from
+func (a *Actor) prepareView(view *proto.EntityView, dst interface{}) (*proto.Schema, error) {
+	if datum := datumLoaderFor(reflect.TypeOf(dst)); datum != nil {
+		datum.Apply(view)
+	}
+	if _, genericResult := dst.(GenericResult); !genericResult {
+		if rel := relationsLoader(reflect.TypeOf(dst)); rel != nil {
+			rel.Apply(view)
+		}
+	}
+
+	// set source
+	for _, p := range view.Properties {
+		p.Source = a.Authorization().GetSource()
+	}
+
+	for _, r := range view.RelationshipByType {
+		r.Source = a.Authorization().GetSource()
+	}
+
+	schema, viewName, err := a.connection.schemaFor(dst)
+	if err != nil {
+		return nil, err
+	}
+	if view.Name == "" {
+		view.Name = viewName
+	}
+	return schema, nil
 }
 
 // Find returns a list of entities matching the given entityType and retrieveProperties
 func (a *Actor) Find(ctx context.Context, entityType string, retrieve RetrieveOption, options ...FindOption) ([]*proto.EntityResponse, error) {
+	resp, err := a.find(ctx, entityType, retrieve, options...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Entities, nil
+}
+
+func (a *Actor) find(ctx context.Context, entityType string, retrieve RetrieveOption, options ...FindOption) (*proto.FindResponse, error) {
 	findRequest := &proto.FindRequest{
 		Authorization: a.Authorization(),
 		Schema:        &proto.Key{Key: entityType, Source: a.Authorization().Source},
@@ -161,16 +192,21 @@ func (a *Actor) Find(ctx context.Context, entityType string, retrieve RetrieveOp
 	findRequest.LabelFilters = fReq.Labels
 	findRequest.RelationOf = fReq.RelationOf
 	findRequest.ParentEntityId = fReq.ParentEntityID
+	findRequest.EntityIds = fReq.EntityIDs
+
+	return a.connection.Find(ctx, findRequest)
+}
 
-	resp, err := a.connection.Find(ctx, findRequest)
+// List returns a list of entities within an active set
+func (a *Actor) List(ctx context.Context, entityType string, retrieveProperties []string, options ...FindOption) ([]*proto.EntityResponse, error) {
+	resp, err := a.list(ctx, entityType, retrieveProperties, options...)
 	if err != nil {
 		return nil, err
 	}
 	return resp.Entities, nil
 }
 
-// List returns a list of entities within an active set
-func (a *Actor) List(ctx context.Context, entityType string, retrieveProperties []string, options ...FindOption) ([]*proto.EntityResponse, error) {
+func (a *Actor) list(ctx context.Context, entityType string, retrieveProperties []string, options ...FindOption) (*proto.ListResponse, error) {
 	listRequest := &proto.ListRequest{
 		Authorization: a.Authorization(),
 		Schema:        &proto.Key{Key: entityType, Source: a.Authorization().Source},
@@ -186,20 +222,17 @@ func (a *Actor) List(ctx context.Context, entityType string, retrieveProperties
 	listRequest.Page = &proto.PageRequest{
 		PerPage:    fReq.PerPage,
 		PageNumber: fReq.PageNumber,
+		AfterId:    fReq.AfterID,
 	}
 
-	if fReq.SortProperty != "" {
-		listRequest.Sort = []*proto.PropertySort{{
-			Property:   fReq.SortProperty,
-			Descending: fReq.SortDescending,
-		}}
+	if schema := a.connection.registeredSchema(entityType); schema != nil {
+		if err := validateSort(schema, fReq.Sort); err != nil {
+			return nil, err
+		}
 	}
+	listRequest.Sort = fReq.Sort
 
-	resp, err := a.connection.List(ctx, listRequest)
-	if err != nil {
-		return nil, err
-	}
-	return resp.Entities, nil
+	return a.connection.List(ctx, listRequest)
 }
 
 // GroupCount returns a list of entities within an active set
@@ -234,8 +267,9 @@ type filterRequest struct {
 	Labels         []*proto.EntityLabel
 	RelationOf     *proto.RelationOf
 	ParentEntityID string
+	EntityIDs      []string
 	PerPage        int32
 	PageNumber     int32
-	SortProperty   string
-	SortDescending bool
+	AfterID        string
+	Sort           []*proto.PropertySort
 }
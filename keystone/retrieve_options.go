@@ -1,6 +1,8 @@
 package keystone
 
 import (
+	"slices"
+
 	"github.com/kubex/keystone-go/proto"
 )
 
@@ -126,6 +128,9 @@ func (l relationshipsLoader) Apply(config *proto.EntityView) {
 		config.RelationshipByType = make([]*proto.Key, 0)
 	}
 	for _, key := range l.keys {
+		if slices.ContainsFunc(config.RelationshipByType, func(k *proto.Key) bool { return k.GetKey() == key }) {
+			continue
+		}
 		config.RelationshipByType = append(config.RelationshipByType, &proto.Key{Key: key})
 	}
 }
@@ -211,7 +216,7 @@ func WithSiblingRelationshipCount(relationType string) RetrieveOption {
 type childSummary struct{ retrieveSummary bool }
 
 func (l childSummary) Apply(config *proto.EntityView) { config.ChildSummary = l.retrieveSummary }
-func WithChildSummary() RetrieveOption                { return relationshipCount{count: true} }
+func WithChildSummary() RetrieveOption                { return childSummary{retrieveSummary: true} }
 
 type descendantTypeCount struct{ entityType, appId, vendorId string }
 
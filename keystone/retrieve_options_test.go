@@ -33,3 +33,56 @@ func TestRetrieveOptions(t *testing.T) {
 		t.Error("Expected 1 property request")
 	}
 }
+
+type testViewChild struct {
+	Note string
+}
+
+type testViewEntity struct {
+	BaseEntity
+	Name    string
+	Email   string `keystone:",pii"`
+	Secret  SecretString
+	Notes   []testViewChild `keystone:"notes"`
+	Payload map[string]any  `keystone:",datum"`
+}
+
+func TestViewFrom(t *testing.T) {
+	view := &proto.EntityView{}
+	ViewFrom(&testViewEntity{}).Apply(view)
+
+	if len(view.Properties) != 2 {
+		t.Fatal("Expected plain and decrypted property requests, got", view.Properties)
+	}
+	if view.Properties[0].Decrypt || view.Properties[0].Properties[0] != "name" {
+		t.Error("Expected name to be requested without decryption, got", view.Properties[0])
+	}
+	if !view.Properties[1].Decrypt || len(view.Properties[1].Properties) != 2 {
+		t.Error("Expected email and secret to be decrypted, got", view.Properties[1])
+	}
+	if len(view.Children) != 1 || view.Children[0].GetType().GetKey() != "notes" {
+		t.Error("Expected notes children to be requested, got", view.Children)
+	}
+	if !view.Datum {
+		t.Error("Expected datum to be requested")
+	}
+}
+
+func TestViewFromCopiesRequests(t *testing.T) {
+	view := &proto.EntityView{}
+	ViewFrom(&testRelCompany{}).Apply(view)
+	if len(view.RelationshipByType) != 1 || view.RelationshipByType[0].GetKey() != "owner" {
+		t.Fatal("Expected the rel= relationship to be requested, got", view.RelationshipByType)
+	}
+	view.Properties[0].Source = &proto.VendorApp{VendorId: "vendor"}
+
+	again := &proto.EntityView{}
+	ViewFrom(&testRelCompany{}).Apply(again)
+	if again.Properties[0].GetSource() != nil {
+		t.Error("Expected changes to an applied view not to affect the cached view")
+	}
+	WithRelationships("owner").Apply(again)
+	if len(again.RelationshipByType) != 1 {
+		t.Error("Expected the relationship not to be requested twice, got", again.RelationshipByType)
+	}
+}
@@ -0,0 +1,101 @@
+package keystone
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/kubex/keystone-go/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// derivedViews caches the view derived for each destination type
+var derivedViews sync.Map
+
+type structView struct{ view *proto.EntityView }
+
+// Apply copies the requests of the cached view, so callers can modify them without affecting other retrievals
+func (l structView) Apply(config *proto.EntityView) {
+	for _, p := range l.view.GetProperties() {
+		config.Properties = append(config.Properties, protobuf.Clone(p).(*proto.PropertyRequest))
+	}
+	for _, c := range l.view.GetChildren() {
+		config.Children = append(config.Children, protobuf.Clone(c).(*proto.ChildRequest))
+	}
+	if len(l.view.GetRelationshipByType()) > 0 {
+		relationships := make([]string, 0, len(l.view.GetRelationshipByType()))
+		for _, r := range l.view.GetRelationshipByType() {
+			relationships = append(relationships, r.GetKey())
+		}
+		relationshipsLoader{keys: relationships}.Apply(config)
+	}
+	config.Datum = config.Datum || l.view.GetDatum()
+	config.ChildSummary = config.ChildSummary || l.view.GetChildSummary()
+}
+
+// ViewFrom is a retrieve option that loads the properties, children, child summary, datum and rel= relationships declared by dst
+// Secret and personal data properties are requested decrypted
+func ViewFrom(dst interface{}) RetrieveOption {
+	t := reflect.TypeOf(dst)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if cached, ok := derivedViews.Load(t); ok {
+		return structView{view: cached.(*proto.EntityView)}
+	}
+
+	view := &proto.EntityView{Datum: hasDatumField(t), ChildSummary: hasChildSummaryField(t)}
+
+	var plain, decrypt []string
+	for _, property := range getProperties(t, "") {
+		if property.GetDataType() == proto.Property_SecureText {
+			decrypt = append(decrypt, property.GetName())
+		} else {
+			plain = append(plain, property.GetName())
+		}
+	}
+	if len(plain) > 0 {
+		view.Properties = append(view.Properties, &proto.PropertyRequest{Properties: plain})
+	}
+	if len(decrypt) > 0 {
+		view.Properties = append(view.Properties, &proto.PropertyRequest{Properties: decrypt, Decrypt: true})
+	}
+
+	for _, childType := range childTypes(t, "") {
+		view.Children = append(view.Children, &proto.ChildRequest{Type: &proto.Key{Key: childType}})
+	}
+
+	if rel := relationsLoader(t); rel != nil {
+		rel.Apply(view)
+	}
+
+	derivedViews.Store(t, view)
+	return structView{view: view}
+}
+
+// childTypes returns the child types hydrated into slice fields of t
+func childTypes(t reflect.Type, prefix string) []string {
+	var types []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous || supportedType(field.Type) {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, prefix)
+		if !fOpt.isProperty() {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Slice:
+			types = append(types, fOpt.name)
+		case reflect.Struct:
+			types = append(types, childTypes(fieldType, fOpt.name+".")...)
+		}
+	}
+	return types
+}
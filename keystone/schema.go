@@ -7,7 +7,9 @@ import (
 	"log"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/kubex/keystone-go/proto"
 )
@@ -115,7 +117,7 @@ func getProperties(t reflect.Type, prefix string) []*proto.Property {
 		}
 
 		fOpt := getFieldOptions(field, prefix)
-		if fOpt.name == "" {
+		if !fOpt.isProperty() {
 			continue
 		}
 
@@ -217,13 +219,16 @@ func getFieldType(fieldType reflect.StructField) (proto.Property_Type, proto.Pro
 
 func getFieldOptions(f reflect.StructField, prefix string) fieldOptions {
 	tag := f.Tag.Get("keystone")
-	opt := fieldOptions{}
+	opt := fieldOptions{childSummary: isChildSummaryType(f.Type)}
 
 	tagParts := strings.Split(tag, ",")
 	for i, part := range tagParts {
 		part = strings.TrimSpace(part)
 		if i == 0 {
-			if part == "" {
+			if relation, ok := strings.CutPrefix(part, "rel="); ok {
+				opt.name = prefix + snakeCase(f.Name)
+				opt.relation = relation
+			} else if part == "" {
 				opt.name = prefix + snakeCase(f.Name)
 			} else if part == "-" {
 				return opt
@@ -232,6 +237,11 @@ func getFieldOptions(f reflect.StructField, prefix string) fieldOptions {
 			}
 			continue
 		}
+		if _, ok := strings.CutPrefix(part, "pattern="); ok {
+			// patterns may contain commas, so take the rest of the tag
+			opt.pattern, opt.tagErr = compilePattern(strings.TrimPrefix(strings.TrimSpace(strings.Join(tagParts[i:], ",")), "pattern="))
+			break
+		}
 		switch part {
 		case "omitempty":
 			opt.omitempty = true
@@ -259,6 +269,12 @@ func getFieldOptions(f reflect.StructField, prefix string) fieldOptions {
 			opt.personalData = true
 		case "user":
 			opt.userInputData = true
+
+		case "datum":
+			opt.datum = true
+
+		default:
+			opt.applyRule(part)
 		}
 	}
 	return opt
@@ -269,6 +285,10 @@ type fieldOptions struct {
 
 	// marshal
 	omitempty bool
+	datum     bool
+	relation  string // relationship type eagerly loaded into the field
+
+	childSummary bool // receives the child summary, rather than a property
 
 	// options
 	unique        bool
@@ -285,6 +305,75 @@ type fieldOptions struct {
 	// Data classification
 	personalData  bool
 	userInputData bool
+
+	// validation
+	minimum *float64
+	maximum *float64
+	length  *int
+	pattern *regexp.Regexp
+	oneOf   []string
+
+	tagErr error // an invalid tag option, such as a pattern that does not compile
+}
+
+// compiledPatterns caches the compiled pattern= expressions of struct tags, or their compile error
+var compiledPatterns sync.Map
+
+type compiledPattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// compilePattern compiles the pattern once, returning the cached result for later fields with the same pattern
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := compiledPatterns.Load(pattern); ok {
+		return cached.(compiledPattern).re, cached.(compiledPattern).err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		err = fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	compiledPatterns.Store(pattern, compiledPattern{re: re, err: err})
+	return re, err
+}
+
+// isProperty returns true when the field is stored as an entity property, rather than excluded or loaded separately
+func (fOpt *fieldOptions) isProperty() bool {
+	return fOpt.name != "" && !fOpt.datum && fOpt.relation == "" && !fOpt.childSummary
+}
+
+// keystoneProvided returns true for properties prefixed with _, such as _entity_id, which keystone fills on load
+// They are hydrated like properties, but are not written as dynamic properties
+func (fOpt *fieldOptions) keystoneProvided() bool {
+	return strings.HasPrefix(fOpt.name, "_")
+}
+
+// applyRule parses a validation rule such as min=1 or oneof=a b c
+// pattern= is parsed by getFieldOptions, as it takes the rest of the tag
+func (fOpt *fieldOptions) applyRule(part string) {
+	rule, value, ok := strings.Cut(part, "=")
+	if !ok {
+		return
+	}
+
+	switch rule {
+	case "rel":
+		fOpt.relation = value
+	case "min":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			fOpt.minimum = &f
+		}
+	case "max":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			fOpt.maximum = &f
+		}
+	case "len":
+		if l, err := strconv.Atoi(value); err == nil {
+			fOpt.length = &l
+		}
+	case "oneof":
+		fOpt.oneOf = strings.Fields(value)
+	}
 }
 
 func (fOpt fieldOptions) applyTo(protoField *proto.Property) {
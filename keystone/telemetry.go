@@ -0,0 +1,218 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// TelemetryWriterConfig configures when a TelemetryWriter flushes
+type TelemetryWriterConfig struct {
+	MaxBatchSize  int                              // Records buffered for a single entity before it is flushed, defaults to 100
+	FlushInterval time.Duration                    // Interval all buffered records are flushed, defaults to 5 seconds
+	MaxPending    int                              // Records buffered across all entities before writes block, defaults to 10000
+	OnError       func(entityID string, err error) // Called when a flush fails, batches keystone could not be reached for are kept for the next flush
+}
+
+// ErrTelemetryWriterClosed is returned when records are written after the TelemetryWriter is closed
+var ErrTelemetryWriterClosed = errors.New("telemetry writer closed")
+
+// TelemetryWriter buffers logs, events and sensor measurements per entity, writing them in batches
+type TelemetryWriter struct {
+	actor   *Actor
+	config  TelemetryWriterConfig
+	mu      sync.Mutex
+	closed  bool
+	batches map[string]*BaseEntity
+	slots   chan struct{}
+	full    chan string
+	stop    chan struct{}
+	done    chan struct{}
+	close   sync.Once
+}
+
+// TelemetryWriter starts a writer that batches telemetry for any entity, Close must be called to flush remaining records
+func (a *Actor) TelemetryWriter(config TelemetryWriterConfig) *TelemetryWriter {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = 10000
+	}
+
+	w := &TelemetryWriter{
+		actor:   a,
+		config:  config,
+		batches: make(map[string]*BaseEntity),
+		slots:   make(chan struct{}, config.MaxPending),
+		full:    make(chan string, 100),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Log buffers a log entry for the entity, blocking while the writer is at capacity
+func (w *TelemetryWriter) Log(ctx context.Context, entityID string, log *proto.EntityLog) error {
+	return w.add(ctx, entityID, func(batch *BaseEntity) {
+		batch.ksEntityLogs = append(batch.ksEntityLogs, log)
+	})
+}
+
+// Event buffers an event for the entity, blocking while the writer is at capacity
+func (w *TelemetryWriter) Event(ctx context.Context, entityID string, event *proto.EntityEvent) error {
+	return w.add(ctx, entityID, func(batch *BaseEntity) {
+		batch.ksEntityEvents = append(batch.ksEntityEvents, event)
+	})
+}
+
+// Measurement buffers a sensor measurement for the entity, blocking while the writer is at capacity
+func (w *TelemetryWriter) Measurement(ctx context.Context, entityID string, measurement *proto.EntitySensorMeasurement) error {
+	return w.add(ctx, entityID, func(batch *BaseEntity) {
+		batch.ksEntitySensorsMeasurements = append(batch.ksEntitySensorsMeasurements, measurement)
+	})
+}
+
+// Flush writes all buffered records
+func (w *TelemetryWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	entityIDs := make([]string, 0, len(w.batches))
+	for entityID := range w.batches {
+		entityIDs = append(entityIDs, entityID)
+	}
+	w.mu.Unlock()
+
+	var errs []error
+	for _, entityID := range entityIDs {
+		if err := w.flushEntity(ctx, entityID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops background flushing, and writes all buffered records
+// Records that fail to flush remain buffered, calling Close again retries them
+func (w *TelemetryWriter) Close(ctx context.Context) error {
+	w.close.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.stop)
+		<-w.done
+	})
+	return w.Flush(ctx)
+}
+
+func (w *TelemetryWriter) add(ctx context.Context, entityID string, apply func(batch *BaseEntity)) error {
+	select {
+	case w.slots <- struct{}{}:
+	case <-w.stop:
+		return ErrTelemetryWriterClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.slots
+		return ErrTelemetryWriterClosed
+	}
+	batch, ok := w.batches[entityID]
+	if !ok {
+		batch = RemoteEntity(entityID)
+		w.batches[entityID] = batch
+	}
+	apply(batch)
+	size := batchSize(batch)
+	w.mu.Unlock()
+
+	if size == w.config.MaxBatchSize {
+		select {
+		case w.full <- entityID:
+		default:
+			// the interval flush will pick up the batch
+		}
+	}
+	return nil
+}
+
+func (w *TelemetryWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			_ = w.Flush(context.Background())
+		case entityID := <-w.full:
+			_ = w.flushEntity(context.Background(), entityID)
+		}
+	}
+}
+
+func (w *TelemetryWriter) flushEntity(ctx context.Context, entityID string) error {
+	w.mu.Lock()
+	batch, ok := w.batches[entityID]
+	delete(w.batches, entityID)
+	w.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// sending clears the staged records, so count them first
+	pending := batchSize(batch)
+	var err error
+	if len(batch.ksEntityEvents) == 0 && len(batch.ksEntitySensorsMeasurements) == 0 {
+		_, err = w.actor.connection.Log(ctx, &proto.LogRequest{
+			Authorization: w.actor.Authorization(),
+			EntityId:      entityID,
+			Logs:          batch.ksEntityLogs,
+		})
+	} else {
+		err = w.actor.RemoteMutate(ctx, batch, "")
+	}
+	if errors.Is(err, ErrMutationQueued) {
+		// the outbox will deliver the batch
+		err = nil
+	}
+
+	if err != nil && w.config.OnError != nil {
+		w.config.OnError(entityID, err)
+	}
+	if err != nil && (retryable(err) || ctx.Err() != nil) {
+		w.requeue(entityID, batch)
+		return err
+	}
+
+	for i := pending; i > 0; i-- {
+		<-w.slots
+	}
+	return err
+}
+
+// requeue returns a batch that failed to send to the buffer, ahead of records written since it was taken
+func (w *TelemetryWriter) requeue(entityID string, batch *BaseEntity) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if newer, ok := w.batches[entityID]; ok {
+		batch.ksEntityLogs = append(batch.ksEntityLogs, newer.ksEntityLogs...)
+		batch.ksEntityEvents = append(batch.ksEntityEvents, newer.ksEntityEvents...)
+		batch.ksEntitySensorsMeasurements = append(batch.ksEntitySensorsMeasurements, newer.ksEntitySensorsMeasurements...)
+	}
+	w.batches[entityID] = batch
+}
+
+func batchSize(batch *BaseEntity) int {
+	return len(batch.ksEntityLogs) + len(batch.ksEntityEvents) + len(batch.ksEntitySensorsMeasurements)
+}
@@ -0,0 +1,136 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTelemetryWriter(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	mu := sync.Mutex{}
+	logs, events := 0, 0
+	server.LogFunc = func(_ context.Context, req *proto.LogRequest) (*proto.LogResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs += len(req.GetLogs())
+		return &proto.LogResponse{}, nil
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events += len(req.GetMutation().GetEvents())
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	w := actor.TelemetryWriter(TelemetryWriterConfig{FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_ = w.Log(ctx, "a", &proto.EntityLog{Message: "log"})
+	}
+	_ = w.Event(ctx, "b", &proto.EntityEvent{Type: &proto.Key{Key: "event"}})
+
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if logs != 3 || events != 1 {
+		t.Errorf("Expected 3 logs and 1 event, got %d logs and %d events", logs, events)
+	}
+}
+
+func TestTelemetryWriter_FailedFlush(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	mu := sync.Mutex{}
+	available := false
+	var received []string
+	server.LogFunc = func(_ context.Context, req *proto.LogRequest) (*proto.LogResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !available {
+			return nil, status.Error(codes.Unavailable, "down")
+		}
+		for _, log := range req.GetLogs() {
+			received = append(received, log.GetMessage())
+		}
+		return &proto.LogResponse{}, nil
+	}
+
+	failures := 0
+	actor := conn.Actor("workspace", "", "", "")
+	w := actor.TelemetryWriter(TelemetryWriterConfig{FlushInterval: time.Hour, OnError: func(string, error) { failures++ }})
+	ctx := context.Background()
+
+	_ = w.Log(ctx, "a", &proto.EntityLog{Message: "first"})
+	if err := w.Flush(ctx); err == nil {
+		t.Fatal("Expected the flush to fail")
+	}
+	_ = w.Log(ctx, "a", &proto.EntityLog{Message: "second"})
+
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(received, []string{"first", "second"}) {
+		t.Error("Expected the failed batch to be retained and sent first, got", received)
+	}
+	if failures != 1 {
+		t.Error("Expected OnError to be called once, got", failures)
+	}
+
+	if err := w.Log(ctx, "a", &proto.EntityLog{Message: "late"}); !errors.Is(err, ErrTelemetryWriterClosed) {
+		t.Error("Expected ErrTelemetryWriterClosed after close, got", err)
+	}
+	if err := w.Close(ctx); err != nil {
+		t.Error("Expected a second close to succeed, got", err)
+	}
+}
+
+func TestTelemetryWriter_ReleasesPending(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	w := actor.TelemetryWriter(TelemetryWriterConfig{FlushInterval: time.Hour, MaxPending: 2})
+	defer w.Close(context.Background())
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		for j := 0; j < 2; j++ {
+			if err := w.Event(ctx, "a", &proto.EntityEvent{Type: &proto.Key{Key: "event"}}); err != nil {
+				t.Fatal("Expected flushed records to release their pending slots, got", err)
+			}
+		}
+		if err := w.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,58 @@
+package keystone
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultMaxFutureTimestamp is how far ahead of the local clock a mutation timestamp may be
+const DefaultMaxFutureTimestamp = 5 * time.Minute
+
+// SetMaxFutureTimestamp sets how far ahead of the local clock a mutation timestamp may be, zero restores the default
+func (c *Connection) SetMaxFutureTimestamp(limit time.Duration) {
+	c.maxFutureTimestamp.Store(int64(limit))
+}
+
+func (c *Connection) futureTimestampLimit() time.Duration {
+	if limit := time.Duration(c.maxFutureTimestamp.Load()); limit > 0 {
+		return limit
+	}
+	return DefaultMaxFutureTimestamp
+}
+
+// validateTimestamps rejects mutation, event, log and measurement timestamps too far in the future
+func (c *Connection) validateTimestamps(mutation *proto.Mutation) error {
+	if err := c.checkTimestamp("mutation", mutation.GetTimestamp()); err != nil {
+		return err
+	}
+	for _, e := range mutation.GetEvents() {
+		if err := c.checkTimestamp("event "+e.GetType().GetKey(), e.GetTime()); err != nil {
+			return err
+		}
+	}
+	for _, l := range mutation.GetLogs() {
+		if err := c.checkTimestamp("log", l.GetTime()); err != nil {
+			return err
+		}
+	}
+	for _, m := range mutation.GetMeasurements() {
+		if err := c.checkTimestamp("measurement "+m.GetSensor(), m.GetAt()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Connection) checkTimestamp(what string, ts *timestamppb.Timestamp) error {
+	if ts == nil {
+		return nil
+	}
+	limit := c.futureTimestampLimit()
+	if ts.AsTime().After(time.Now().Add(limit)) {
+		return fmt.Errorf("%s timestamp %s is more than %s in the future", what, ts.AsTime().Format(time.RFC3339), limit)
+	}
+	return nil
+}
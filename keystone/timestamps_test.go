@@ -0,0 +1,38 @@
+package keystone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+func TestValidateTimestamps(t *testing.T) {
+	c := &Connection{}
+	e := &BaseEntity{}
+	e.AddKeystoneEventAt("imported", nil, time.Now().Add(-24*time.Hour))
+	e.AddKeystoneSensorMeasurementAt("temp", 21, time.Now().Add(-time.Hour))
+
+	m := &proto.Mutation{Events: e.GetKeystoneEvents(), Measurements: e.GetKeystoneSensorMeasurements()}
+	if err := c.validateTimestamps(m); err != nil {
+		t.Error("Expected backfilled timestamps to be valid, got", err)
+	}
+
+	e.AddKeystoneEventAt("future", nil, time.Now().Add(time.Hour))
+	m.Events = e.GetKeystoneEvents()
+	if err := c.validateTimestamps(m); err == nil {
+		t.Error("Expected future event timestamp to be rejected")
+	}
+
+	c.SetMaxFutureTimestamp(2 * time.Hour)
+	if err := c.validateTimestamps(m); err != nil {
+		t.Error("Expected the connection limit to allow the event, got", err)
+	}
+
+	req := &proto.MutateRequest{Mutation: &proto.Mutation{}}
+	at := time.Now().Add(-48 * time.Hour)
+	MutateAt(at).apply(req)
+	if !req.GetMutation().GetTimestamp().AsTime().Equal(at) {
+		t.Error("Expected MutateAt to set the mutation timestamp")
+	}
+}
@@ -0,0 +1,87 @@
+package keystone
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// Get retrieves the entity with the given ID as a T, loading the fields declared by T when no retrieve options are given
+func Get[T any](ctx context.Context, actor *Actor, entityID string, retrieve ...RetrieveOption) (*T, error) {
+	dst := new(T)
+	if len(retrieve) == 0 {
+		retrieve = []RetrieveOption{ViewFrom(dst)}
+	}
+	if err := actor.GetByID(ctx, entityID, dst, retrieve...); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// FindAll returns the entities of type T matching the given options, loading the fields declared by T when retrieve is nil
+func FindAll[T any](ctx context.Context, actor *Actor, retrieve RetrieveOption, options ...FindOption) ([]*T, error) {
+	schema, viewName, err := actor.connection.schemaFor(new(T))
+	if err != nil {
+		return nil, err
+	}
+	if retrieve == nil && viewName != "" {
+		retrieve = WithView(viewName)
+	} else if retrieve == nil {
+		retrieve = ViewFrom(new(T))
+	} else if viewName != "" {
+		retrieve = RetrieveOptions(WithView(viewName), retrieve)
+	}
+	if rel := relationsLoader(reflect.TypeOf(new(T))); rel != nil {
+		retrieve = RetrieveOptions(retrieve, rel)
+	}
+	if datum := datumLoaderFor(reflect.TypeOf(new(T))); datum != nil {
+		retrieve = RetrieveOptions(retrieve, datum)
+	}
+	entityType := schema.GetType()
+	resp, err := actor.Find(ctx, entityType, retrieve, options...)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAll[T](ctx, actor, resp)
+}
+
+// ListAll returns the entities of type T within an active set
+func ListAll[T any](ctx context.Context, actor *Actor, retrieveProperties []string, options ...FindOption) ([]*T, error) {
+	entityType, err := actor.typeKey(new(T))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := actor.List(ctx, entityType, retrieveProperties, options...)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAll[T](ctx, actor, resp)
+}
+
+// typeKey registers the schema for dst, returning its type key
+func (a *Actor) typeKey(dst interface{}) (string, error) {
+	schema, _, err := a.connection.schemaFor(dst)
+	if err != nil {
+		return "", err
+	}
+	return schema.GetType(), nil
+}
+
+// unmarshalAll decodes each response as a T, then eager loads any rel= fields across all of them
+func unmarshalAll[T any](ctx context.Context, actor *Actor, resp []*proto.EntityResponse) ([]*T, error) {
+	result := make([]*T, 0, len(resp))
+	dsts := make([]reflect.Value, 0, len(resp))
+	for _, r := range resp {
+		dst := new(T)
+		if err := unmarshal(ctx, actor, r, dst); err != nil {
+			return nil, err
+		}
+		result = append(result, dst)
+		dsts = append(dsts, reflect.ValueOf(dst))
+	}
+	if actor == nil {
+		return result, nil
+	}
+	return result, actor.loadRelations(ctx, resp, dsts, 0)
+}
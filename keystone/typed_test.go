@@ -0,0 +1,47 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testTypedEntity struct {
+	BaseEntity
+	Name string
+}
+
+func TestFindAll(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		if req.GetSchema().GetKey() != "test-typed-entity" {
+			t.Error("Expected schema key test-typed-entity, got", req.GetSchema().GetKey())
+		}
+		return &proto.FindResponse{Entities: []*proto.EntityResponse{
+			{Entity: &proto.Entity{EntityId: "b", State: proto.EntityState_Active}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Bee"}}}},
+			{Entity: &proto.Entity{EntityId: "a"}, Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ant"}}}},
+		}}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	result, err := FindAll[testTypedEntity](context.Background(), &actor, WithProperties("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatal("Expected 2 entities, got", len(result))
+	}
+	if result[0].GetKeystoneID() != "b" || result[0].Name != "Bee" || result[1].Name != "Ant" {
+		t.Error("Unexpected entities", result[0], result[1])
+	}
+	if result[0].KeystoneState() != proto.EntityState_Active {
+		t.Error("Expected entity details to be populated, got state", result[0].KeystoneState())
+	}
+}
@@ -1,6 +1,7 @@
 package keystone
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/kubex/keystone-go/proto"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
 func UnmarshalAppend(dstPtr any, resp ...*proto.EntityResponse) error {
@@ -57,7 +59,18 @@ func UnmarshalAppend(dstPtr any, resp ...*proto.EntityResponse) error {
 	return nil
 }
 
+// Unmarshal hydrates dst from the entity response
 func Unmarshal(resp *proto.EntityResponse, dst interface{}) error {
+	return unmarshal(context.Background(), nil, resp, dst)
+}
+
+func unmarshal(ctx context.Context, actor *Actor, resp *proto.EntityResponse, dst interface{}) error {
+	if actor != nil && !datumFromSource(resp.GetDatum(), actor.VendorApp()) {
+		// only decode datum written by this app
+		resp = protobuf.Clone(resp).(*proto.EntityResponse)
+		resp.Datum = nil
+	}
+
 	entityPropertyMap := makeEntityPropertyMap(resp)
 
 	if resp.GetEntity() != nil {
@@ -113,16 +126,33 @@ func Unmarshal(resp *proto.EntityResponse, dst interface{}) error {
 		entityPropertyMap[variant] = &proto.EntityProperty{Property: variant, Value: &proto.Value{Int: cnt}}
 	}
 
-	if entityWithRelationships, ok := dst.(EntityRelationshipProvider); ok {
+	if entityWithRelationships, ok := dst.(EntityRelationshipSyncer); ok {
+		entityWithRelationships.HydrateKeystoneRelationships(resp.GetRelationships())
+	} else if entityWithRelationships, ok := dst.(EntityRelationshipProvider); ok {
 		entityWithRelationships.SetKeystoneRelationships(resp.GetRelationships())
 	}
+
+	if entityWithLabels, ok := dst.(EntityLabelSyncer); ok {
+		entityWithLabels.HydrateKeystoneLabels(resp.GetLabels())
+	}
 	err := entityResponseToDst(entityPropertyMap, resp.Children, dst, "")
+	if err == nil {
+		err = unmarshalDatum(resp.GetDatum(), dst)
+	}
+	unmarshalChildSummaries(resp.GetChildSummary(), dst)
 
 	if baseEntity, ok := dst.(Entity); ok {
 		baseEntity.SetKeystoneID(resp.GetEntity().GetEntityId())
 	}
 
-	return err
+	if tracker, ok := dst.(loadTracker); ok {
+		tracker.setKeystoneLastLoad(resp)
+	}
+
+	if err != nil {
+		return err
+	}
+	return afterLoad(ctx, actor, dst)
 }
 
 func UnmarshalGeneric(resp *proto.EntityResponse, dst GenericResult) error {
@@ -180,7 +210,9 @@ func entityResponseToDst(entityPropertyMap map[string]*proto.EntityProperty, chi
 		field := dstVal.Type().Field(i)
 		fieldValue := dstVal.Field(i)
 		fieldOpt := getFieldOptions(field, prefix)
-		fieldOpt.name = prefix + fieldOpt.name
+		if !fieldOpt.isProperty() {
+			continue
+		}
 		if supportedType(field.Type) {
 			setFieldValue(field, fieldValue, fieldOpt, entityPropertyMap)
 		} else if field.IsExported() {
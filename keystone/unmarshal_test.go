@@ -1,6 +1,8 @@
 package keystone
 
 import (
+	"bytes"
+	"context"
 	"github.com/kubex/keystone-go/proto"
 	"testing"
 )
@@ -62,3 +64,108 @@ func (e *testBaseChildEntity) AggregateValue() int64 {
 }
 
 func (e *testBaseChildEntity) SetAggregateValue(v int64) {}
+
+func TestUnmarshalDatum(t *testing.T) {
+	src := &testDatumEntity{Name: "entity", Settings: testDatumSettings{Theme: "dark", Limit: 5}}
+	data, err := marshalDatum(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &testDatumEntity{}
+	resp := &proto.EntityResponse{Datum: &proto.EntityDatum{Data: data}}
+	if err := Unmarshal(resp, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Settings != src.Settings {
+		t.Error("Expected", src.Settings, "got", dst.Settings)
+	}
+}
+
+type testDatumEntity struct {
+	BaseEntity
+	Name     string
+	Settings testDatumSettings `keystone:",datum"`
+}
+
+type testDatumSettings struct {
+	Theme string
+	Limit int
+}
+
+func TestClearDatum(t *testing.T) {
+	src := &testDatumEntity{Name: "entity"}
+	if data, _ := marshalDatum(src); data != nil {
+		t.Error("Expected no datum for an entity not loaded with one, got", string(data))
+	}
+
+	resp := &proto.EntityResponse{Datum: &proto.EntityDatum{Data: []byte(`{"Limit": 5, "Theme": "dark"}`)}}
+	if err := Unmarshal(resp, src); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := marshalDatum(src); data != nil {
+		t.Error("Expected an unchanged datum not to be written, got", string(data))
+	}
+
+	src.Settings.Limit = 6
+	if data, _ := marshalDatum(src); string(data) != `{"Theme":"dark","Limit":6}` {
+		t.Error("Expected the changed datum to be written, got", string(data))
+	}
+
+	src.Settings = testDatumSettings{}
+	data, err := marshalDatum(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, clearedDatum) {
+		t.Error("Expected the datum to be cleared, got", string(data))
+	}
+
+	refreshLastLoad(src, &proto.Mutation{Datum: data})
+	if data, _ := marshalDatum(src); data != nil {
+		t.Error("Expected a cleared datum not to be cleared again, got", string(data))
+	}
+
+	reused := &testDatumEntity{Settings: testDatumSettings{Theme: "light"}}
+	if err := Unmarshal(&proto.EntityResponse{Datum: &proto.EntityDatum{Data: clearedDatum}}, reused); err != nil {
+		t.Fatal(err)
+	}
+	if reused.Settings != (testDatumSettings{}) {
+		t.Error("Expected a cleared datum to reset the field, got", reused.Settings)
+	}
+}
+
+func TestGetManyDatum(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+	server.FindFunc = func(_ context.Context, req *proto.FindRequest) (*proto.FindResponse, error) {
+		if !req.GetView().GetDatum() {
+			t.Error("Expected the datum to be requested")
+		}
+		return &proto.FindResponse{Entities: []*proto.EntityResponse{
+			{Entity: &proto.Entity{EntityId: "a"}, Datum: &proto.EntityDatum{Data: []byte(`{"Theme":"dark"}`)}},
+			{Entity: &proto.Entity{EntityId: "b"}, Datum: &proto.EntityDatum{
+				Data:   []byte(`{"Theme":"light"}`),
+				Source: &proto.VendorApp{VendorId: "other", AppId: "app"},
+			}},
+		}}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	var result []*testDatumEntity
+	if _, err := actor.GetMany(context.Background(), []string{"a", "b"}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Settings.Theme != "dark" {
+		t.Fatal("Expected the datum to be decoded", result)
+	}
+	if result[1].Settings.Theme != "" {
+		t.Error("Expected a datum written by another app to be ignored, got", result[1].Settings)
+	}
+}
@@ -0,0 +1,154 @@
+package keystone
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpsertResult is the outcome of an upsert
+type UpsertResult struct {
+	Created  bool
+	EntityID string
+}
+
+// Upsert creates the entity, or updates the existing entity matching any of the unique properties
+// Created is only reported when keystone accepted the entity as new, a create rejected because a matching
+// entity now exists is retried as an update of that entity
+func (a *Actor) Upsert(ctx context.Context, src interface{}, uniqueProps []string, comment string, options ...MutateOption) (UpsertResult, error) {
+	result := UpsertResult{}
+	rawEntity, ok := src.(Entity)
+	if !ok {
+		return result, errors.New("upsert requires a keystone entity")
+	}
+	if len(uniqueProps) == 0 {
+		return result, errors.New("upsert requires at least one unique property")
+	}
+
+	settings := getMutateSettings(options)
+	update := append(slices.Clone(options), OnConflictUseID(uniqueProps...))
+
+	if rawEntity.GetKeystoneID() == "" {
+		existingID, err := a.lookupUnique(ctx, src, uniqueProps, settings.dryRun)
+		if err != nil {
+			return result, err
+		}
+		if existingID == "" {
+			mResp, err := a.mutate(ctx, src, comment, options...)
+			if settings.dryRun {
+				result.Created = true
+				return result, err
+			}
+			if err == nil {
+				result.Created = true
+				result.EntityID = mResp.GetEntityId()
+				return result, a.readBack(ctx, result.EntityID, src, settings)
+			}
+
+			// the create may have been rejected by an entity created since the lookup
+			if existingID, _ = a.lookupUnique(ctx, src, uniqueProps, settings.dryRun); existingID == "" {
+				return result, err
+			}
+		}
+		rawEntity.SetKeystoneID(existingID)
+		if settings.dryRun {
+			// leave src unchanged, the plan records the matched entity
+			defer rawEntity.SetKeystoneID("")
+		}
+	}
+
+	mResp, err := a.mutate(ctx, src, comment, update...)
+	if err != nil {
+		return result, err
+	}
+	if settings.dryRun {
+		result.EntityID = rawEntity.GetKeystoneID()
+		return result, nil
+	}
+
+	if mResp.GetEntityId() != "" && mResp.GetEntityId() != rawEntity.GetKeystoneID() {
+		rawEntity.SetKeystoneID(mResp.GetEntityId())
+	}
+	result.EntityID = rawEntity.GetKeystoneID()
+	return result, a.readBack(ctx, result.EntityID, src, settings)
+}
+
+// ReadBack retrieves the stored entity into src after an upsert
+func ReadBack(retrieve ...RetrieveOption) MutateOption {
+	return readBack{retrieve: retrieve}
+}
+
+type readBack struct {
+	retrieve []RetrieveOption
+}
+
+func (m readBack) apply(*proto.MutateRequest) {}
+func (m readBack) applySettings(settings *mutateSettings) {
+	settings.readBack = append(settings.readBack, m.retrieve...)
+}
+
+// readBack retrieves the entity into src when ReadBack options were provided
+func (a *Actor) readBack(ctx context.Context, entityID string, src interface{}, settings mutateSettings) error {
+	if len(settings.readBack) == 0 {
+		return nil
+	}
+	return a.GetByID(ctx, entityID, src, settings.readBack...)
+}
+
+// lookupUnique returns the ID of the entity matching any of the unique property values on src
+// A dry run looks up by the planned schema, rather than defining it with keystone
+func (a *Actor) lookupUnique(ctx context.Context, src interface{}, uniqueProps []string, dryRun bool) (string, error) {
+	var schema *proto.Schema
+	if dryRun {
+		schema = a.connection.plannedSchema(src)
+	} else {
+		schema = a.connection.definedSchema(src)
+	}
+
+	schemaID := schema.GetId()
+	if schemaID == "" {
+		schemaID = schema.GetType()
+	}
+
+	encoder := &PropertyEncoder{}
+	properties := makeEntityPropertyMap(&proto.EntityResponse{Properties: encoder.Marshal(src).GetProperties()})
+
+	for _, property := range uniqueProps {
+		uniqueID := uniqueValue(properties[property].GetValue())
+		if uniqueID == "" {
+			continue
+		}
+
+		resp, err := a.connection.Retrieve(ctx, &proto.EntityRequest{
+			Authorization: a.Authorization(),
+			Schema:        &proto.Key{Key: schema.GetType(), Source: a.Authorization().GetSource()},
+			UniqueId:      &proto.IDLookup{SchemaId: schemaID, Property: property, UniqueId: uniqueID},
+			View:          &proto.EntityView{},
+		})
+		if status.Code(err) == codes.NotFound {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		if id := resp.GetEntity().GetEntityId(); id != "" {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+func uniqueValue(value *proto.Value) string {
+	if value.GetText() != "" {
+		return value.GetText()
+	}
+	if value.GetInt() != 0 {
+		return strconv.FormatInt(value.GetInt(), 10)
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testUpsertEntity struct {
+	BaseEntity
+	Email string `keystone:",unique"`
+}
+
+func TestActor_Upsert(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+
+	existing := map[string]string{"known@example.com": "abc"}
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		if id, ok := existing[req.GetUniqueId().GetUniqueId()]; ok {
+			return &proto.EntityResponse{Entity: &proto.Entity{EntityId: id}}, nil
+		}
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		if req.GetEntityId() == "" {
+			return &proto.MutateResponse{Success: true, EntityId: "new"}, nil
+		}
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+
+	res, err := actor.Upsert(context.Background(), &testUpsertEntity{Email: "known@example.com"}, []string{"email"}, "upsert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created || res.EntityID != "abc" {
+		t.Error("Expected existing entity abc to be matched, got", res)
+	}
+
+	res, err = actor.Upsert(context.Background(), &testUpsertEntity{Email: "new@example.com"}, []string{"email"}, "upsert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Created || res.EntityID != "new" {
+		t.Error("Expected entity to be created, got", res)
+	}
+
+	if len(res.EntityID) == 0 {
+		t.Error("Expected an entity ID")
+	}
+}
+
+func TestActor_UpsertConcurrentCreate(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		return req.GetSchema(), nil
+	}
+
+	// the entity is created by another client between the lookup and the create
+	lookups := 0
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		if req.GetUniqueId() == nil {
+			return &proto.EntityResponse{
+				Entity:     &proto.Entity{EntityId: req.GetEntityId()},
+				Properties: []*proto.EntityProperty{{Property: "email", Value: &proto.Value{Text: "race@example.com"}}},
+			}, nil
+		}
+		lookups++
+		if lookups == 1 {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return &proto.EntityResponse{Entity: &proto.Entity{EntityId: "other"}}, nil
+	}
+	var requests []*proto.MutateRequest
+	server.MutateFunc = func(_ context.Context, req *proto.MutateRequest) (*proto.MutateResponse, error) {
+		requests = append(requests, req)
+		if req.GetEntityId() == "" {
+			return &proto.MutateResponse{ErrorCode: 409, ErrorMessage: "unique property email is taken"}, nil
+		}
+		return &proto.MutateResponse{Success: true, EntityId: req.GetEntityId()}, nil
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	src := &testUpsertEntity{Email: "race@example.com"}
+	res, err := actor.Upsert(context.Background(), src, []string{"email"}, "upsert", ReadBack(WithProperties("email")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created || res.EntityID != "other" {
+		t.Error("Expected the concurrently created entity to be updated, got", res)
+	}
+	if len(requests) != 2 || len(requests[0].GetConflictUniquePropertyAcquire()) != 0 || requests[1].GetEntityId() != "other" {
+		t.Error("Expected a create followed by an update of the matched entity, got", requests)
+	}
+	if src.Email != "race@example.com" || src.GetKeystoneID() != "other" {
+		t.Error("Expected the entity to be read back, got", src)
+	}
+}
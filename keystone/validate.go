@@ -0,0 +1,194 @@
+package keystone
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+// ValidationError is returned when an entity fails client side validation
+type ValidationError struct {
+	Properties []PropertyValidationError
+}
+
+// PropertyValidationError describes a single property failing validation
+type PropertyValidationError struct {
+	Property string
+	Rule     string
+	Message  string
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Properties))
+	for _, p := range e.Properties {
+		msgs = append(msgs, p.Property+" "+p.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks an entity against the rules declared in its keystone struct tags
+// required is only enforced on creation, and immutable properties may not differ from the last load
+// An invalid rule, such as a pattern that does not compile, is returned as a tag error rather than a ValidationError
+func Validate(src interface{}) error {
+	v := &validator{creating: true}
+	if e, ok := src.(Entity); ok {
+		v.creating = e.GetKeystoneID() == ""
+	}
+	if tracker, ok := src.(loadTracker); ok {
+		v.loaded = makeEntityPropertyMap(tracker.keystoneLastLoad())
+	}
+
+	value := reflect.ValueOf(src)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	v.validateFields(value, value.Type(), "")
+	if v.tagErr != nil {
+		return v.tagErr
+	}
+	if len(v.failures) > 0 {
+		return &ValidationError{Properties: v.failures}
+	}
+	return nil
+}
+
+type validator struct {
+	creating bool
+	loaded   map[string]*proto.EntityProperty
+	failures []PropertyValidationError
+	tagErr   error
+}
+
+func (v *validator) fail(property, rule, message string) {
+	v.failures = append(v.failures, PropertyValidationError{Property: property, Rule: rule, Message: message})
+}
+
+func (v *validator) validateFields(value reflect.Value, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field, fieldValue := t.Field(i), value.Field(i)
+		if field.Anonymous {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				v.validateFields(fieldValue, fieldValue.Type(), prefix)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fOpt := getFieldOptions(field, prefix)
+		if fOpt.tagErr != nil && v.tagErr == nil {
+			v.tagErr = fmt.Errorf("keystone tag on %s: %w", field.Name, fOpt.tagErr)
+		}
+		if !fOpt.isProperty() {
+			continue
+		}
+
+		if supportedType(field.Type) {
+			prop, isEmpty := entityPropertyFromField(fieldValue, field.Type, fOpt)
+			dataType, _ := getFieldType(field)
+			v.validateProperty(fOpt, dataType, prop.GetValue(), isEmpty)
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() == reflect.Struct {
+			v.validateFields(fieldValue, fieldValue.Type(), fOpt.name+".")
+		}
+	}
+}
+
+func (v *validator) validateProperty(fOpt fieldOptions, dataType proto.Property_Type, value *proto.Value, isEmpty bool) {
+	// checked before empty values return, so clearing an immutable property also fails
+	if loaded, ok := v.loaded[fOpt.name]; ok && fOpt.immutable && !valuesEqual(loaded.GetValue(), value) {
+		v.fail(fOpt.name, "immutable", "cannot be changed once set")
+	}
+
+	if isEmpty {
+		if fOpt.required && v.creating {
+			v.fail(fOpt.name, "required", "is required")
+		}
+		return
+	}
+
+	if size, ok := measureValue(dataType, value); ok {
+		if fOpt.minimum != nil && size < *fOpt.minimum {
+			v.fail(fOpt.name, "min", fmt.Sprintf("must be at least %v", *fOpt.minimum))
+		}
+		if fOpt.maximum != nil && size > *fOpt.maximum {
+			v.fail(fOpt.name, "max", fmt.Sprintf("must be at most %v", *fOpt.maximum))
+		}
+		if fOpt.length != nil && size != float64(*fOpt.length) {
+			v.fail(fOpt.name, "len", fmt.Sprintf("must have a length of %d", *fOpt.length))
+		}
+	}
+
+	text := valueText(dataType, value)
+	if fOpt.pattern != nil && !fOpt.pattern.MatchString(text) {
+		v.fail(fOpt.name, "pattern", "must match "+fOpt.pattern.String())
+	}
+
+	if len(fOpt.oneOf) > 0 && !slices.Contains(fOpt.oneOf, text) {
+		v.fail(fOpt.name, "oneof", "must be one of "+strings.Join(fOpt.oneOf, ", "))
+	}
+}
+
+// measureValue returns the number for numeric types, or the length for text and collections
+func measureValue(dataType proto.Property_Type, value *proto.Value) (float64, bool) {
+	switch dataType {
+	case proto.Property_Number, proto.Property_Amount:
+		return float64(value.GetInt()), true
+	case proto.Property_Float:
+		return value.GetFloat(), true
+	case proto.Property_Text, proto.Property_SecureText, proto.Property_VerifyText:
+		return float64(utf8.RuneCountInString(valueText(dataType, value))), true
+	case proto.Property_Strings, proto.Property_StringSet:
+		return float64(len(value.GetArray().GetStrings())), true
+	case proto.Property_Ints, proto.Property_IntSet:
+		return float64(len(value.GetArray().GetInts())), true
+	case proto.Property_KeyValue:
+		return float64(len(value.GetArray().GetKeyValue())), true
+	case proto.Property_Bytes:
+		return float64(len(value.GetRaw())), true
+	}
+	return 0, false
+}
+
+// valueText returns the value as text for pattern and oneof matching
+func valueText(dataType proto.Property_Type, value *proto.Value) string {
+	switch dataType {
+	case proto.Property_Number:
+		return strconv.FormatInt(value.GetInt(), 10)
+	case proto.Property_Float:
+		return strconv.FormatFloat(value.GetFloat(), 'f', -1, 64)
+	case proto.Property_SecureText, proto.Property_VerifyText:
+		if value.GetSecureText() != "" {
+			return value.GetSecureText()
+		}
+	}
+	return value.GetText()
+}
@@ -0,0 +1,98 @@
+package keystone
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testValidateEntity struct {
+	BaseEntity
+	Name   string `keystone:",required,min=3,max=10"`
+	Status string `keystone:",oneof=active inactive"`
+	Code   string `keystone:",immutable,pattern=^[A-Z]+$"`
+	Score  int    `keystone:",max=100"`
+}
+
+func TestValidate(t *testing.T) {
+	err := Validate(&testValidateEntity{Status: "deleted", Code: "abc", Score: 101})
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatal("Expected ValidationError, got", err)
+	}
+
+	rules := map[string]string{}
+	for _, p := range vErr.Properties {
+		rules[p.Property] = p.Rule
+	}
+
+	expect := map[string]string{"name": "required", "status": "oneof", "code": "pattern", "score": "max"}
+	for prop, rule := range expect {
+		if rules[prop] != rule {
+			t.Errorf("Expected %s to fail %s, got %q", prop, rule, rules[prop])
+		}
+	}
+}
+
+type testPatternEntity struct {
+	Code string `keystone:",required,pattern=^[A-Z]{1,3}-\\d{2,}$"`
+}
+
+func TestValidatePatternWithComma(t *testing.T) {
+	if err := Validate(&testPatternEntity{Code: "AB-123"}); err != nil {
+		t.Error("Expected AB-123 to match, got", err)
+	}
+	if err := Validate(&testPatternEntity{Code: "ABCD-1"}); err == nil {
+		t.Error("Expected ABCD-1 to fail the pattern")
+	}
+}
+
+func TestValidateImmutable(t *testing.T) {
+	e := &testValidateEntity{}
+	if err := Unmarshal(&proto.EntityResponse{
+		Entity:     &proto.Entity{EntityId: "abc"},
+		Properties: []*proto.EntityProperty{{Property: "code", Value: &proto.Value{Text: "ABC"}}},
+	}, e); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Validate(e); err != nil {
+		t.Error("Expected unchanged entity to be valid, got", err)
+	}
+
+	e.Code = "XYZ"
+	var vErr *ValidationError
+	if err := Validate(e); !errors.As(err, &vErr) || vErr.Properties[0].Rule != "immutable" {
+		t.Error("Expected immutable failure, got", err)
+	}
+}
+
+type testInvalidPatternEntity struct {
+	Code string `keystone:",pattern=[A-Z"`
+}
+
+func TestValidateInvalidPattern(t *testing.T) {
+	err := Validate(&testInvalidPatternEntity{Code: "A"})
+	var vErr *ValidationError
+	if err == nil || errors.As(err, &vErr) {
+		t.Error("Expected an invalid pattern to be a tag error, got", err)
+	}
+}
+
+func TestValidateImmutableCleared(t *testing.T) {
+	e := &testValidateEntity{}
+	if err := Unmarshal(&proto.EntityResponse{
+		Entity:     &proto.Entity{EntityId: "abc"},
+		Properties: []*proto.EntityProperty{{Property: "code", Value: &proto.Value{Text: "ABC"}}},
+	}, e); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Code = ""
+	var vErr *ValidationError
+	if err := Validate(e); !errors.As(err, &vErr) || vErr.Properties[0].Rule != "immutable" {
+		t.Error("Expected clearing an immutable property to fail, got", err)
+	}
+}
@@ -0,0 +1,93 @@
+package keystone
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/kubex/keystone-go/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// viewProjections records the projection types of views created by ViewOf, keyed by the view name and content
+// so repeated ViewOf calls for the same projection share an entry
+var viewProjections = struct {
+	sync.RWMutex
+	byView map[string][]reflect.Type
+	types  map[reflect.Type]bool
+}{byView: make(map[string][]reflect.Type), types: make(map[reflect.Type]bool)}
+
+// projection links a projection struct to the entity type and named view it reads
+type projection struct {
+	entity reflect.Type
+	view   string
+}
+
+// ViewOf declares a named view loading the fields of the projection struct T, for use in TypeDefinition.Views
+// Once the entity type is registered, retrieving into T selects the named view on the entity schema
+// The entity type must be registered before retrieving into T
+func ViewOf[T any](name string) *proto.EntityView {
+	t := reflect.TypeOf(new(T)).Elem()
+	view := &proto.EntityView{}
+	ViewFrom(new(T)).Apply(view)
+	view.Name = name
+
+	key := viewProjectionKey(view)
+	viewProjections.Lock()
+	defer viewProjections.Unlock()
+	if !slices.Contains(viewProjections.byView[key], t) {
+		viewProjections.byView[key] = append(viewProjections.byView[key], t)
+	}
+	viewProjections.types[t] = true
+	return view
+}
+
+func viewProjectionKey(view *proto.EntityView) string {
+	data, _ := protobuf.MarshalOptions{Deterministic: true}.Marshal(view)
+	return string(data)
+}
+
+// registerProjections records the projection types for the views declared by the entity type, registerLock must be held
+func (c *Connection) registerProjections(entity reflect.Type, def TypeDefinition) {
+	viewProjections.RLock()
+	defer viewProjections.RUnlock()
+	for _, view := range def.Views {
+		for _, t := range viewProjections.byView[viewProjectionKey(view)] {
+			c.projections[t] = projection{entity: entity, view: view.GetName()}
+		}
+	}
+}
+
+// projectionOf returns the entity type and view name for a projection struct
+func (c *Connection) projectionOf(t reflect.Type) (projection, bool) {
+	c.registerLock.RLock()
+	defer c.registerLock.RUnlock()
+	p, ok := c.projections[t]
+	return p, ok
+}
+
+// isProjection returns true when t has been declared as a projection with ViewOf
+func isProjection(t reflect.Type) bool {
+	viewProjections.RLock()
+	defer viewProjections.RUnlock()
+	return viewProjections.types[t]
+}
+
+// schemaFor registers and returns the schema dst is read from, along with the view to select for projection structs
+func (c *Connection) schemaFor(dst interface{}) (*proto.Schema, string, error) {
+	t := reflect.TypeOf(dst)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	viewName := ""
+	if p, ok := c.projectionOf(t); ok {
+		dst = reflect.New(p.entity).Interface()
+		viewName = p.view
+	} else if isProjection(t) {
+		return nil, "", fmt.Errorf("%s is a projection, register the entity type declaring its view before retrieving it", t)
+	}
+
+	return c.definedSchema(dst), viewName, nil
+}
@@ -0,0 +1,88 @@
+package keystone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubex/keystone-go/proto"
+)
+
+type testViewUser struct {
+	BaseEntity
+	Name  string
+	Email string
+	Bio   string
+}
+
+type testViewUserSummary struct {
+	BaseEntity
+	Name string
+}
+
+func (u testViewUser) GetKeystoneDefinition() TypeDefinition {
+	return TypeDefinition{Views: []*proto.EntityView{ViewOf[testViewUserSummary]("summary")}}
+}
+
+func TestViewOf(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	var defined *proto.SchemaRequest
+	server.DefineFunc = func(_ context.Context, req *proto.SchemaRequest) (*proto.Schema, error) {
+		defined = req
+		return req.GetSchema(), nil
+	}
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		if req.GetSchema().GetKey() != "test-view-user" {
+			t.Error("Expected projection to read the test-view-user schema, got", req.GetSchema().GetKey())
+		}
+		if req.GetView().GetName() != "summary" {
+			t.Error("Expected the summary view to be selected, got", req.GetView().GetName())
+		}
+		return &proto.EntityResponse{
+			Entity:     &proto.Entity{EntityId: req.GetEntityId()},
+			Properties: []*proto.EntityProperty{{Property: "name", Value: &proto.Value{Text: "Ant"}}},
+		}, nil
+	}
+
+	conn.RegisterTypes(testViewUser{})
+	conn.SyncSchema().Wait()
+	if len(defined.GetViews()) != 1 || defined.GetViews()[0].GetName() != "summary" {
+		t.Fatal("Expected the summary view to be defined, got", defined.GetViews())
+	}
+	if props := defined.GetViews()[0].GetProperties(); len(props) != 1 || len(props[0].GetProperties()) != 1 || props[0].GetProperties()[0] != "name" {
+		t.Error("Expected the summary view to load name, got", props)
+	}
+
+	actor := conn.Actor("workspace", "", "", "")
+	summary, err := Get[testViewUserSummary](context.Background(), &actor, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Name != "Ant" || summary.GetKeystoneID() != "abc" {
+		t.Error("Unexpected summary", summary)
+	}
+}
+
+func TestViewOf_Unregistered(t *testing.T) {
+	conn, server, listener, s := MockConnection()
+	go func() { _ = s.Serve(listener) }()
+	defer s.Stop()
+
+	server.RetrieveFunc = func(_ context.Context, req *proto.EntityRequest) (*proto.EntityResponse, error) {
+		t.Error("Expected no retrieve for an unregistered projection")
+		return &proto.EntityResponse{}, nil
+	}
+
+	// declare the projection without registering the entity type on this connection
+	testViewUser{}.GetKeystoneDefinition()
+
+	actor := conn.Actor("workspace", "", "", "")
+	if _, err := Get[testViewUserSummary](context.Background(), &actor, "abc"); err == nil {
+		t.Fatal("Expected an error retrieving a projection before its entity type is registered")
+	}
+	if len(conn.typeRegister) != 0 {
+		t.Error("Expected the projection not to be registered as its own schema")
+	}
+}